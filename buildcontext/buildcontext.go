@@ -0,0 +1,275 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package buildcontext resolves, enumerates and hashes the content of service build contexts
+package buildcontext
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/compose-spec/compose-go/v2/utils"
+	"github.com/moby/patternmatcher"
+	"github.com/moby/patternmatcher/ignorefile"
+	"github.com/opencontainers/go-digest"
+)
+
+// LocalDir returns the directory for a local build context, or false if context is a remote resource or an
+// image reference. Relative paths are resolved from workingDir
+func LocalDir(workingDir string, context string) (string, bool) {
+	if context == "" || strings.Contains(context, "://") || strings.HasPrefix(context, types.ServicePrefix) {
+		return "", false
+	}
+	for _, prefix := range []string{"github.com/", "git@"} {
+		if strings.HasPrefix(context, prefix) {
+			return "", false
+		}
+	}
+	if !filepath.IsAbs(context) {
+		context = filepath.Join(workingDir, context)
+	}
+	return filepath.Clean(context), true
+}
+
+// Dockerfile returns the path to the Dockerfile for a build from local context directory, or an
+// empty string when build uses dockerfile_inline
+func Dockerfile(contextDir string, build *types.BuildConfig) string {
+	if build.DockerfileInline != "" {
+		return ""
+	}
+	dockerfile := build.Dockerfile
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+	if !filepath.IsAbs(dockerfile) {
+		dockerfile = filepath.Join(contextDir, dockerfile)
+	}
+	return dockerfile
+}
+
+// Ignore reads the .dockerignore file at the root of a local build context. A missing file results in a
+// matcher which doesn't exclude any path
+func Ignore(contextDir string) (*patternmatcher.PatternMatcher, error) {
+	return readIgnoreFile(filepath.Join(contextDir, ".dockerignore"))
+}
+
+// IgnoreForDockerfile reads the .dockerignore file which applies to a build from a local context. As for
+// Docker, a `<Dockerfile>.dockerignore` file next to the Dockerfile takes precedence over the context root
+// .dockerignore. A relative dockerfile path is resolved from context directory
+func IgnoreForDockerfile(contextDir string, dockerfile string) (*patternmatcher.PatternMatcher, error) {
+	if dockerfile != "" {
+		if !filepath.IsAbs(dockerfile) {
+			dockerfile = filepath.Join(contextDir, dockerfile)
+		}
+		filename := dockerfile + ".dockerignore"
+		if _, err := os.Stat(filename); err == nil {
+			return readIgnoreFile(filename)
+		}
+	}
+	return Ignore(contextDir)
+}
+
+func readIgnoreFile(filename string) (*patternmatcher.PatternMatcher, error) {
+	f, err := os.Open(filename)
+	if errors.Is(err, fs.ErrNotExist) {
+		return patternmatcher.New(nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	patterns, err := ignorefile.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	m, err := patternmatcher.New(patterns)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return m, nil
+}
+
+// Walk enumerates the files in dir which are not excluded by ignore, as slash separated paths relative to dir,
+// in lexical order. Symbolic links are not followed
+func Walk(dir string, ignore *patternmatcher.PatternMatcher) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		excluded, err := ignore.MatchesOrParentMatches(rel)
+		if err != nil {
+			return err
+		}
+		if excluded {
+			if d.IsDir() && !ignore.Exclusions() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			files = append(files, rel)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// Files enumerates the files included in a service local build context
+func Files(project *types.Project, service types.ServiceConfig) ([]string, error) {
+	if service.Build == nil {
+		return nil, fmt.Errorf("service %q has no build section", service.Name)
+	}
+	dir, ok := LocalDir(project.WorkingDir, service.Build.Context)
+	if !ok {
+		return nil, fmt.Errorf("service %q build context %s is not local", service.Name, service.Build.Context)
+	}
+	ignore, err := IgnoreForDockerfile(dir, Dockerfile(dir, service.Build))
+	if err != nil {
+		return nil, err
+	}
+	return Walk(dir, ignore)
+}
+
+// Hash computes a stable digest for a service build, based on the content of the local build context and
+// local additional contexts, the Dockerfile or dockerfile_inline, build args and target. Remote contexts are
+// only identified by their reference
+func Hash(project *types.Project, service types.ServiceConfig) (digest.Digest, error) {
+	build := service.Build
+	if build == nil {
+		return "", fmt.Errorf("service %q has no build section", service.Name)
+	}
+	h := sha256.New()
+	record(h, "context")
+	dir, local := LocalDir(project.WorkingDir, build.Context)
+	if local {
+		dockerfile := Dockerfile(dir, build)
+		ignore, err := IgnoreForDockerfile(dir, dockerfile)
+		if err != nil {
+			return "", err
+		}
+		if err := hashTree(h, dir, ignore); err != nil {
+			return "", err
+		}
+		if dockerfile != "" {
+			b, err := os.ReadFile(dockerfile)
+			if err != nil {
+				return "", err
+			}
+			record(h, "dockerfile", string(b))
+		}
+	} else {
+		record(h, build.Context)
+		if build.DockerfileInline == "" {
+			record(h, "dockerfile", build.Dockerfile)
+		}
+	}
+	if build.DockerfileInline != "" {
+		record(h, "dockerfile_inline", build.DockerfileInline)
+	}
+	record(h, "target", build.Target)
+	for _, name := range utils.MapKeys(build.Args) {
+		if v := build.Args[name]; v != nil {
+			record(h, "arg", name, *v)
+		} else {
+			record(h, "arg", name)
+		}
+	}
+	for _, name := range utils.MapKeys(build.AdditionalContexts) {
+		context := build.AdditionalContexts[name]
+		record(h, "additional_context", name)
+		dir, local := LocalDir(project.WorkingDir, context)
+		if !local {
+			record(h, context)
+			continue
+		}
+		ignore, err := Ignore(dir)
+		if err != nil {
+			return "", err
+		}
+		if err := hashTree(h, dir, ignore); err != nil {
+			return "", err
+		}
+	}
+	return digest.NewDigestFromEncoded(digest.SHA256, hex.EncodeToString(h.Sum(nil))), nil
+}
+
+// hashTree writes the path, mode and content of files in dir not excluded by ignore
+func hashTree(h hash.Hash, dir string, ignore *patternmatcher.PatternMatcher) error {
+	files, err := Walk(dir, ignore)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		path := filepath.Join(dir, filepath.FromSlash(file))
+		fi, err := os.Lstat(path)
+		if err != nil {
+			return err
+		}
+		switch {
+		case fi.Mode()&fs.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			record(h, "symlink", file, target)
+		case fi.Mode().IsRegular():
+			content, err := hashFile(path)
+			if err != nil {
+				return err
+			}
+			record(h, "file", file, fi.Mode().Perm().String(), content)
+		default:
+			record(h, "special", file, fi.Mode().String())
+		}
+	}
+	return nil
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// record writes fields with their length, so that distinct records can't produce the same byte sequence
+func record(h hash.Hash, fields ...string) {
+	for _, f := range fields {
+		fmt.Fprintf(h, "%d:%s", len(f), f)
+	}
+	h.Write([]byte{'\n'})
+}
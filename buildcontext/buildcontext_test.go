@@ -0,0 +1,184 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package buildcontext
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		assert.NilError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		assert.NilError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+}
+
+func contextProject(t *testing.T) *types.Project {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"app/Dockerfile":          "FROM alpine\nCOPY . /app\n",
+		"app/.dockerignore":       "node_modules\n*.log\n",
+		"app/main.go":             "package main\n",
+		"app/debug.log":           "log\n",
+		"app/node_modules/x/y.js": "x\n",
+		"app/docs/README.md":      "doc\n",
+		"assets/logo.svg":         "<svg/>\n",
+	})
+	version := "1.0"
+	return &types.Project{
+		WorkingDir: dir,
+		Services: types.Services{
+			"app": {
+				Name: "app",
+				Build: &types.BuildConfig{
+					Context:            "app",
+					Args:               types.MappingWithEquals{"VERSION": &version, "PROXY": nil},
+					AdditionalContexts: types.Mapping{"assets": "assets", "base": "docker-image://alpine"},
+				},
+			},
+		},
+	}
+}
+
+func TestLocalDir(t *testing.T) {
+	for context, expected := range map[string]string{
+		"app":                            "/work/app",
+		"/abs/dir":                       "/abs/dir",
+		"https://github.com/foo/bar.git": "",
+		"git@github.com:foo/bar.git":     "",
+		"service:base":                   "",
+		"docker-image://alpine":          "",
+	} {
+		dir, ok := LocalDir("/work", context)
+		assert.Equal(t, ok, expected != "", context)
+		assert.Equal(t, dir, expected, context)
+	}
+}
+
+func TestDockerfile(t *testing.T) {
+	assert.Equal(t, Dockerfile("/work/app", &types.BuildConfig{}), "/work/app/Dockerfile")
+	assert.Equal(t, Dockerfile("/work/app", &types.BuildConfig{Dockerfile: "build/app.Dockerfile"}), "/work/app/build/app.Dockerfile")
+	assert.Equal(t, Dockerfile("/work/app", &types.BuildConfig{Dockerfile: "/abs/Dockerfile"}), "/abs/Dockerfile")
+	assert.Equal(t, Dockerfile("/work/app", &types.BuildConfig{DockerfileInline: "FROM alpine"}), "")
+}
+
+func TestIgnore(t *testing.T) {
+	dir := t.TempDir()
+	m, err := Ignore(dir)
+	assert.NilError(t, err)
+	excluded, err := m.MatchesOrParentMatches("main.go")
+	assert.NilError(t, err)
+	assert.Assert(t, !excluded)
+
+	writeFiles(t, dir, map[string]string{".dockerignore": "# comment\nnode_modules\n*.log\n!keep.log\n"})
+	m, err = Ignore(dir)
+	assert.NilError(t, err)
+	for file, expected := range map[string]bool{
+		"main.go":           false,
+		"debug.log":         true,
+		"keep.log":          false,
+		"node_modules/x.js": true,
+	} {
+		excluded, err := m.MatchesOrParentMatches(file)
+		assert.NilError(t, err)
+		assert.Equal(t, excluded, expected, file)
+	}
+
+	writeFiles(t, dir, map[string]string{".dockerignore": "[a-\n"})
+	_, err = Ignore(dir)
+	assert.ErrorContains(t, err, ".dockerignore")
+}
+
+func TestFiles(t *testing.T) {
+	project := contextProject(t)
+	files, err := Files(project, project.Services["app"])
+	assert.NilError(t, err)
+	assert.DeepEqual(t, files, []string{".dockerignore", "Dockerfile", "docs/README.md", "main.go"})
+
+	// a Dockerfile specific ignore file takes precedence
+	writeFiles(t, project.WorkingDir, map[string]string{
+		"app/build/app.Dockerfile":              "FROM alpine\n",
+		"app/build/app.Dockerfile.dockerignore": "docs\nbuild\n",
+	})
+	app := project.Services["app"]
+	app.Build.Dockerfile = "build/app.Dockerfile"
+	files, err = Files(project, app)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, files, []string{".dockerignore", "Dockerfile", "debug.log", "main.go", "node_modules/x/y.js"})
+}
+
+func TestHash(t *testing.T) {
+	project := contextProject(t)
+	hash := func() string {
+		t.Helper()
+		d, err := Hash(project, project.Services["app"])
+		assert.NilError(t, err)
+		return d.String()
+	}
+	initial := hash()
+	assert.Equal(t, hash(), initial)
+
+	// ignored files don't change the hash
+	writeFiles(t, project.WorkingDir, map[string]string{"app/debug.log": "more logs\n"})
+	assert.Equal(t, hash(), initial)
+
+	writeFiles(t, project.WorkingDir, map[string]string{"app/main.go": "package main\n\nfunc main() {}\n"})
+	changed := hash()
+	assert.Assert(t, changed != initial)
+
+	writeFiles(t, project.WorkingDir, map[string]string{"assets/logo.svg": "<svg></svg>\n"})
+	assert.Assert(t, hash() != changed)
+	changed = hash()
+
+	version := "2.0"
+	project.Services["app"].Build.Args["VERSION"] = &version
+	assert.Assert(t, hash() != changed)
+	changed = hash()
+
+	project.Services["app"].Build.Target = "prod"
+	assert.Assert(t, hash() != changed)
+}
+
+func TestHashDockerfileInline(t *testing.T) {
+	project := &types.Project{
+		Services: types.Services{
+			"app": {
+				Name: "app",
+				Build: &types.BuildConfig{
+					Context:          "https://github.com/foo/bar.git",
+					DockerfileInline: "FROM alpine\n",
+				},
+			},
+		},
+	}
+	first, err := Hash(project, project.Services["app"])
+	assert.NilError(t, err)
+	project.Services["app"].Build.DockerfileInline = "FROM busybox\n"
+	second, err := Hash(project, project.Services["app"])
+	assert.NilError(t, err)
+	assert.Assert(t, first != second)
+
+	_, err = Hash(project, types.ServiceConfig{Name: "db"})
+	assert.Error(t, err, `service "db" has no build section`)
+}
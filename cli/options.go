@@ -24,6 +24,7 @@ import (
 	"strconv"
 	"strings"
 
+	"filippo.io/age"
 	"github.com/sirupsen/logrus"
 	"go.yaml.in/yaml/v4"
 
@@ -306,6 +307,44 @@ func WithDotEnv(o *ProjectOptions) error {
 	return nil
 }
 
+// LintEnvFiles checks env files used to set project environment for common mistakes.
+// Variables are looked up from ProjectOptions.Environment. Files which extension denotes another format
+// than dotenv are skipped
+func (o *ProjectOptions) LintEnvFiles() ([]dotenv.LintFinding, error) {
+	var findings []dotenv.LintFinding
+	for _, file := range o.EnvFiles {
+		if dotenv.DetectFormat(file) != dotenv.DotEnv {
+			continue
+		}
+		f, err := dotenv.LintFile(file, o.Environment.Resolve)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, f...)
+	}
+	return findings, nil
+}
+
+// WithAgeIdentities sets identities to decrypt env_file using the age format. Identities only apply to
+// this project and take precedence over COMPOSE_AGE_KEY and COMPOSE_AGE_KEY_FILE
+func WithAgeIdentities(identities ...age.Identity) ProjectOptionsFn {
+	return func(o *ProjectOptions) error {
+		o.loadOptions = append(o.loadOptions, loader.WithAgeIdentities(identities...))
+		return nil
+	}
+}
+
+// WithAgeKeyFile reads identities from key file to decrypt env_file using the age format, see WithAgeIdentities
+func WithAgeKeyFile(file string) ProjectOptionsFn {
+	return func(o *ProjectOptions) error {
+		identities, err := dotenv.ReadAgeIdentities(file)
+		if err != nil {
+			return err
+		}
+		return WithAgeIdentities(identities...)(o)
+	}
+}
+
 // WithInterpolation set ProjectOptions to enable/skip interpolation
 func WithInterpolation(interpolation bool) ProjectOptionsFn {
 	return func(o *ProjectOptions) error {
@@ -316,6 +355,20 @@ func WithInterpolation(interpolation bool) ProjectOptionsFn {
 	}
 }
 
+// WithKeysInterpolation set ProjectOptions to also interpolate mapping keys, like labels or environment names
+func WithKeysInterpolation(o *ProjectOptions) error {
+	o.loadOptions = append(o.loadOptions, loader.WithKeysInterpolation)
+	return nil
+}
+
+// WithTargetVersion set ProjectOptions to reject attributes not supported by the target docker compose version
+func WithTargetVersion(version string) ProjectOptionsFn {
+	return func(o *ProjectOptions) error {
+		o.loadOptions = append(o.loadOptions, loader.WithTargetVersion(version))
+		return nil
+	}
+}
+
 // WithNormalization set ProjectOptions to enable/skip normalization
 func WithNormalization(normalization bool) ProjectOptionsFn {
 	return func(o *ProjectOptions) error {
@@ -17,16 +17,21 @@
 package cli
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 
+	"filippo.io/age"
 	"github.com/compose-spec/compose-go/v2/types"
 	"gotest.tools/v3/assert"
 
 	"github.com/compose-spec/compose-go/v2/consts"
+	"github.com/compose-spec/compose-go/v2/dotenv"
 	"github.com/compose-spec/compose-go/v2/utils"
 )
 
@@ -384,3 +389,68 @@ func TestEnvVariablePrecedence(t *testing.T) {
 		})
 	}
 }
+
+func TestLintEnvFiles(t *testing.T) {
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, ".env")
+	assert.NilError(t, os.WriteFile(envFile, []byte("FOO=1\nFOO=2\n"), 0o600))
+	jsonFile := filepath.Join(dir, "env.json")
+	assert.NilError(t, os.WriteFile(jsonFile, []byte(`{"FOO": "1"}`), 0o600))
+
+	opts, err := NewProjectOptions(nil, WithEnvFiles(envFile, jsonFile))
+	assert.NilError(t, err)
+	findings, err := opts.LintEnvFiles()
+	assert.NilError(t, err)
+	assert.Equal(t, len(findings), 1)
+	assert.Equal(t, findings[0].Filename, envFile)
+	assert.Equal(t, findings[0].Rule, dotenv.LintRuleDuplicateKey)
+}
+
+func TestAgeIdentitiesScopedToProject(t *testing.T) {
+	dir := t.TempDir()
+	identity, err := age.GenerateX25519Identity()
+	assert.NilError(t, err)
+	var encrypted bytes.Buffer
+	assert.NilError(t, dotenv.EncryptAge(&encrypted, strings.NewReader("FOO=bar\n"), identity.Recipient()))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "secret.env.age"), encrypted.Bytes(), 0o600))
+	keyFile := filepath.Join(dir, "key.txt")
+	assert.NilError(t, os.WriteFile(keyFile, []byte(identity.String()+"\n"), 0o600))
+	compose := filepath.Join(dir, "compose.yaml")
+	assert.NilError(t, os.WriteFile(compose, []byte("services:\n  app:\n    image: alpine\n    env_file:\n      - path: secret.env.age\n        format: age\n    labels:\n      key: ${COMPOSE_AGE_KEY:-unset}\n"), 0o600))
+
+	load := func(opts ...ProjectOptionsFn) (*types.Project, error) {
+		options, err := NewProjectOptions([]string{compose}, append([]ProjectOptionsFn{WithName("age")}, opts...)...)
+		if err != nil {
+			return nil, err
+		}
+		return ProjectFromOptions(context.TODO(), options)
+	}
+
+	var wg sync.WaitGroup
+	for _, opt := range []ProjectOptionsFn{WithAgeIdentities(identity), WithAgeKeyFile(keyFile)} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p, err := load(opt)
+			assert.Check(t, err)
+			if err == nil {
+				assert.Check(t, *p.Services["app"].Environment["FOO"] == "bar")
+			}
+		}()
+	}
+	wg.Wait()
+
+	// identities set for other projects don't leak
+	_, err = load()
+	assert.ErrorContains(t, err, "no age identity to decrypt")
+
+	// explicit identities are not exposed to interpolation, and can't be overridden by environment
+	other, err := age.GenerateX25519Identity()
+	assert.NilError(t, err)
+	p, err := load(WithAgeKeyFile(keyFile), WithEnv([]string{"COMPOSE_AGE_KEY=" + other.String()}))
+	assert.NilError(t, err)
+	assert.Equal(t, *p.Services["app"].Environment["FOO"], "bar")
+	p, err = load(WithAgeIdentities(identity))
+	assert.NilError(t, err)
+	assert.Equal(t, p.Services["app"].Labels["key"], "unset")
+}
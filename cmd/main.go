@@ -24,6 +24,7 @@ import (
 	"os"
 
 	"github.com/compose-spec/compose-go/v2/cli"
+	"github.com/compose-spec/compose-go/v2/graph"
 	"go.yaml.in/yaml/v4"
 )
 
@@ -32,7 +33,12 @@ func main() {
 		fmt.Println(`
 Validates a compose file conforms to the Compose Specification
 
-Usage: compose-spec [OPTIONS] COMPOSE_FILE [COMPOSE_OVERRIDE_FILE]`)
+Usage: compose-spec [OPTIONS] COMPOSE_FILE [COMPOSE_OVERRIDE_FILE]
+       compose-spec graph [OPTIONS] COMPOSE_FILE [COMPOSE_OVERRIDE_FILE]`)
+	}
+	if len(os.Args) > 1 && os.Args[1] == "graph" {
+		graphMain(os.Args[2:])
+		return
 	}
 
 	var skipInterpolation, skipResolvePaths, skipNormalization, skipConsistencyCheck bool
@@ -90,6 +96,65 @@ Usage: compose-spec [OPTIONS] COMPOSE_FILE [COMPOSE_OVERRIDE_FILE]`)
 	fmt.Println(string(raw))
 }
 
+// graphMain renders the service dependency graph
+func graphMain(args []string) {
+	var format string
+	var profiles []string
+	var implicit bool
+
+	flags := flag.NewFlagSet("graph", flag.ExitOnError)
+	flags.StringVar(&format, "format", "dot", "Output format (dot|mermaid).")
+	flags.BoolVar(&implicit, "implicit", false, "Include dependencies implied by attributes other than depends_on.")
+	flags.Func("profile", "Enable a profile.", func(s string) error {
+		profiles = append(profiles, s)
+		return nil
+	})
+	_ = flags.Parse(args)
+
+	wd, err := os.Getwd()
+	if err != nil {
+		exitError("can't determine current directory", err)
+	}
+
+	options, err := cli.NewProjectOptions(flags.Args(),
+		cli.WithWorkingDirectory(wd),
+		cli.WithOsEnv,
+		cli.WithDotEnv,
+		cli.WithConfigFileEnv,
+		cli.WithDefaultConfigPath,
+		cli.WithProfiles(profiles),
+	)
+	if err != nil {
+		exitError("failed to configure project options", err)
+	}
+
+	project, err := options.LoadProject(context.Background())
+	if err != nil {
+		exitError("failed to load project", err)
+	}
+
+	var graphOptions []func(*graph.Options)
+	if implicit {
+		graphOptions = append(graphOptions, graph.WithImplicitDependencies)
+	}
+	g, err := graph.New(project, graphOptions...)
+	if err != nil {
+		exitError("failed to build dependency graph", err)
+	}
+
+	switch format {
+	case "dot":
+		err = g.WriteDOT(os.Stdout)
+	case "mermaid":
+		err = g.WriteMermaid(os.Stdout)
+	default:
+		err = fmt.Errorf("unsupported output format %s", format)
+	}
+	if err != nil {
+		exitError("failed to render dependency graph", err)
+	}
+}
+
 func exitError(message string, err error) {
 	fmt.Fprintf(os.Stderr, "%s: %v", message, err)
 	os.Exit(1)
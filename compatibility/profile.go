@@ -0,0 +1,125 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compatibility
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/compose-spec/compose-go/v2/errdefs"
+	"github.com/compose-spec/compose-go/v2/tree"
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// Finding reports an attribute of a compose model a target runtime can't honor.
+// Err is errdefs.ErrUnsupported when the runtime ignores or rejects the attribute,
+// errdefs.ErrIncompatible when it conflicts with another part of the model
+type Finding struct {
+	Path   tree.Path
+	Reason string
+	Err    error
+}
+
+func (f Finding) Error() string {
+	return fmt.Sprintf("%s: %s: %v", f.Path, f.Reason, f.Err)
+}
+
+func (f Finding) Unwrap() error {
+	return f.Err
+}
+
+// Profile describes the capabilities of a target runtime
+type Profile interface {
+	// Name is the identifier the profile is registered with
+	Name() string
+	// Check returns findings for the attributes of project the runtime can't honor
+	Check(project *types.Project) []Finding
+}
+
+// Rule checks a project for a set of attributes a runtime can't honor
+type Rule func(project *types.Project) []Finding
+
+type ruleProfile struct {
+	name  string
+	rules []Rule
+}
+
+// NewProfile creates a Profile applying rules in sequence
+func NewProfile(name string, rules ...Rule) Profile {
+	return ruleProfile{name: name, rules: rules}
+}
+
+func (p ruleProfile) Name() string {
+	return p.name
+}
+
+func (p ruleProfile) Check(project *types.Project) []Finding {
+	var findings []Finding
+	for _, rule := range p.rules {
+		findings = append(findings, rule(project)...)
+	}
+	slices.SortStableFunc(findings, func(a, b Finding) int {
+		return comparePaths(a.Path, b.Path)
+	})
+	return findings
+}
+
+func comparePaths(a, b tree.Path) int {
+	return slices.Compare(a.Parts(), b.Parts())
+}
+
+var profiles = map[string]Profile{}
+
+// Register makes a Profile available by name, replacing any profile previously registered with the same name
+func Register(profile Profile) {
+	profiles[profile.Name()] = profile
+}
+
+// Get returns the Profile registered by name
+func Get(name string) (Profile, bool) {
+	p, ok := profiles[name]
+	return p, ok
+}
+
+// Names returns the sorted names of registered profiles
+func Names() []string {
+	var names []string
+	for name := range profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Check runs the profile registered by name against project
+func Check(project *types.Project, name string) ([]Finding, error) {
+	p, ok := Get(name)
+	if !ok {
+		return nil, fmt.Errorf("runtime profile %q: %w", name, errdefs.ErrNotFound)
+	}
+	return p.Check(project), nil
+}
+
+// Unsupported creates a Finding for an attribute the runtime ignores or rejects
+func Unsupported(path tree.Path, reason string) Finding {
+	return Finding{Path: path, Reason: reason, Err: errdefs.ErrUnsupported}
+}
+
+// Incompatible creates a Finding for an attribute which conflicts with another part of the model on the runtime
+func Incompatible(path tree.Path, reason string) Finding {
+	return Finding{Path: path, Reason: reason, Err: errdefs.ErrIncompatible}
+}
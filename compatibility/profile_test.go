@@ -0,0 +1,142 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compatibility
+
+import (
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/errdefs"
+	"github.com/compose-spec/compose-go/v2/tree"
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func paths(findings []Finding) []string {
+	var p []string
+	for _, f := range findings {
+		p = append(p, f.Path.String())
+	}
+	return p
+}
+
+func testProject() *types.Project {
+	return &types.Project{
+		Services: types.Services{
+			"web": {
+				Name:          "web",
+				Build:         &types.BuildConfig{Context: "."},
+				ContainerName: "web",
+				Links:         []string{"db"},
+				Deploy: &types.DeployConfig{
+					Replicas:  intPtr(2),
+					Placement: types.Placement{Constraints: []string{"node.role==manager"}},
+				},
+			},
+			"db": {
+				Name:        "db",
+				Image:       "postgres",
+				Restart:     "always",
+				VolumesFrom: []string{"web"},
+			},
+		},
+		Secrets: types.Secrets{
+			"token": {External: true},
+		},
+		Configs: types.Configs{
+			"app": {Content: "debug=true"},
+		},
+	}
+}
+
+func TestComposeEngineProfile(t *testing.T) {
+	findings, err := Check(testProject(), ComposeEngine)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, paths(findings), []string{
+		"secrets.token.external",
+		"services.web.container_name",
+		"services.web.deploy.placement",
+	})
+	assert.Assert(t, errdefs.IsUnsupportedError(findings[0]))
+	assert.Assert(t, errdefs.IsIncompatibleError(findings[1]))
+	assert.Error(t, findings[1], "services.web.container_name: container name must be unique, service can't be scaled beyond a single container: incompatible attribute")
+}
+
+func TestSwarmProfile(t *testing.T) {
+	findings, err := Check(testProject(), Swarm)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, paths(findings), []string{
+		"configs.app.content",
+		"services.db.restart",
+		"services.db.volumes_from",
+		"services.web.build",
+		"services.web.container_name",
+		"services.web.links",
+	})
+	for _, f := range findings {
+		assert.Assert(t, errdefs.IsUnsupportedError(f), f.Error())
+	}
+}
+
+func TestPodmanProfile(t *testing.T) {
+	findings, err := Check(testProject(), Podman)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, paths(findings), []string{
+		"services.db.volumes_from",
+		"services.web.container_name",
+		"services.web.deploy.placement",
+		"services.web.links",
+	})
+}
+
+func TestServiceNameWithDot(t *testing.T) {
+	project := &types.Project{
+		Services: types.Services{
+			"web.app": {Name: "web.app", Build: &types.BuildConfig{Context: "."}},
+		},
+	}
+	findings, err := Check(project, Swarm)
+	assert.NilError(t, err)
+	assert.Equal(t, len(findings), 1)
+	assert.DeepEqual(t, findings[0].Path.Parts(), []string{"services", "web👻app", "build"})
+}
+
+func TestRegisterProfile(t *testing.T) {
+	Register(NewProfile("internal", func(project *types.Project) []Finding {
+		var findings []Finding
+		for _, name := range project.ServiceNames() {
+			if project.Services[name].Image == "" {
+				findings = append(findings, Unsupported(tree.NewPath("services", name, "image"), "images must be pre-built"))
+			}
+		}
+		return findings
+	}))
+	t.Cleanup(func() {
+		delete(profiles, "internal")
+	})
+	assert.DeepEqual(t, Names(), []string{ComposeEngine, "internal", Podman, Swarm})
+
+	findings, err := Check(testProject(), "internal")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, paths(findings), []string{"services.web.image"})
+
+	_, err = Check(testProject(), "unknown")
+	assert.Assert(t, errdefs.IsNotFoundError(err))
+}
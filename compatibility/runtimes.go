@@ -0,0 +1,209 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package compatibility
+
+import (
+	"github.com/compose-spec/compose-go/v2/tree"
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+const (
+	// ComposeEngine is the profile for docker compose running against a standalone Docker engine
+	ComposeEngine = "compose"
+	// Swarm is the profile for `docker stack deploy` on a Swarm cluster
+	Swarm = "swarm"
+	// Podman is the profile for podman-compose
+	Podman = "podman"
+)
+
+func init() {
+	Register(NewProfile(ComposeEngine,
+		unsupportedServiceAttributes([]serviceAttribute{
+			{path: "deploy.placement", isSet: hasPlacement, reason: "placement only applies to Swarm services"},
+			{path: "deploy.update_config", isSet: func(s types.ServiceConfig) bool {
+				return s.Deploy != nil && s.Deploy.UpdateConfig != nil
+			}, reason: "rolling updates only apply to Swarm services"},
+			{path: "deploy.rollback_config", isSet: func(s types.ServiceConfig) bool {
+				return s.Deploy != nil && s.Deploy.RollbackConfig != nil
+			}, reason: "rollbacks only apply to Swarm services"},
+			{path: "deploy.endpoint_mode", isSet: func(s types.ServiceConfig) bool {
+				return s.Deploy != nil && s.Deploy.EndpointMode != ""
+			}, reason: "service discovery by endpoint mode only applies to Swarm services"},
+		}),
+		containerNameWithReplicas,
+		externalSecrets,
+	))
+	Register(NewProfile(Swarm,
+		unsupportedServiceAttributes([]serviceAttribute{
+			{path: "build", isSet: func(s types.ServiceConfig) bool {
+				return s.Build != nil
+			}, reason: "stack deploy doesn't build images, image must be pushed to a registry"},
+			{path: "cgroup_parent", isSet: func(s types.ServiceConfig) bool {
+				return s.CgroupParent != ""
+			}, reason: "cgroup parent can't be set on Swarm tasks"},
+			{path: "container_name", isSet: func(s types.ServiceConfig) bool {
+				return s.ContainerName != ""
+			}, reason: "Swarm assigns task container names"},
+			{path: "depends_on", isSet: func(s types.ServiceConfig) bool {
+				return len(s.DependsOn) > 0
+			}, reason: "Swarm starts services concurrently, startup order is not guaranteed"},
+			{path: "develop", isSet: func(s types.ServiceConfig) bool {
+				return s.Develop != nil
+			}, reason: "watch mode requires docker compose"},
+			{path: "devices", isSet: func(s types.ServiceConfig) bool {
+				return len(s.Devices) > 0
+			}, reason: "devices can't be mapped into Swarm tasks"},
+			{path: "external_links", isSet: func(s types.ServiceConfig) bool {
+				return len(s.ExternalLinks) > 0
+			}, reason: "legacy links are not available on overlay networks"},
+			{path: "links", isSet: func(s types.ServiceConfig) bool {
+				return len(s.Links) > 0
+			}, reason: "legacy links are not available on overlay networks, services are reachable by name"},
+			{path: "models", isSet: func(s types.ServiceConfig) bool {
+				return len(s.Models) > 0
+			}, reason: "models require docker compose"},
+			{path: "network_mode", isSet: func(s types.ServiceConfig) bool {
+				return s.NetworkMode != ""
+			}, reason: "Swarm tasks are attached to networks, use networks instead"},
+			{path: "privileged", isSet: func(s types.ServiceConfig) bool {
+				return s.Privileged
+			}, reason: "Swarm tasks can't run privileged"},
+			{path: "provider", isSet: func(s types.ServiceConfig) bool {
+				return s.Provider != nil
+			}, reason: "provider services require docker compose"},
+			{path: "restart", isSet: func(s types.ServiceConfig) bool {
+				return s.Restart != ""
+			}, reason: "Swarm ignores restart, use deploy.restart_policy instead"},
+			{path: "security_opt", isSet: func(s types.ServiceConfig) bool {
+				return len(s.SecurityOpt) > 0
+			}, reason: "security options can't be set on Swarm tasks"},
+			{path: "userns_mode", isSet: func(s types.ServiceConfig) bool {
+				return s.UserNSMode != ""
+			}, reason: "user namespace mode can't be set on Swarm tasks"},
+			{path: "volumes_from", isSet: func(s types.ServiceConfig) bool {
+				return len(s.VolumesFrom) > 0
+			}, reason: "tasks may be scheduled on distinct nodes, use named volumes instead"},
+		}),
+		swarmFileObjects,
+	))
+	Register(NewProfile(Podman,
+		unsupportedServiceAttributes([]serviceAttribute{
+			{path: "deploy.placement", isSet: hasPlacement, reason: "placement only applies to Swarm services"},
+			{path: "develop", isSet: func(s types.ServiceConfig) bool {
+				return s.Develop != nil
+			}, reason: "watch mode is not implemented by podman-compose"},
+			{path: "links", isSet: func(s types.ServiceConfig) bool {
+				return len(s.Links) > 0
+			}, reason: "containers share the pod network, services are reachable by name"},
+			{path: "models", isSet: func(s types.ServiceConfig) bool {
+				return len(s.Models) > 0
+			}, reason: "models are not implemented by podman-compose"},
+			{path: "provider", isSet: func(s types.ServiceConfig) bool {
+				return s.Provider != nil
+			}, reason: "provider services are not implemented by podman-compose"},
+			{path: "use_api_socket", isSet: func(s types.ServiceConfig) bool {
+				return s.UseAPISocket
+			}, reason: "the Docker API socket is not available"},
+			{path: "volumes_from", isSet: func(s types.ServiceConfig) bool {
+				return len(s.VolumesFrom) > 0
+			}, reason: "volumes_from is not implemented by podman-compose, use named volumes instead"},
+		}),
+		containerNameWithReplicas,
+	))
+}
+
+// serviceAttribute is a service attribute, as a path relative to the service, a runtime can't honor
+type serviceAttribute struct {
+	path   tree.Path
+	isSet  func(s types.ServiceConfig) bool
+	reason string
+}
+
+func unsupportedServiceAttributes(attributes []serviceAttribute) Rule {
+	return func(project *types.Project) []Finding {
+		var findings []Finding
+		for _, name := range project.ServiceNames() {
+			service := project.Services[name]
+			for _, attr := range attributes {
+				if attr.isSet(service) {
+					findings = append(findings, Unsupported(servicePath(name, attr.path), attr.reason))
+				}
+			}
+		}
+		return findings
+	}
+}
+
+func servicePath(service string, path tree.Path) tree.Path {
+	return tree.NewPath(string(tree.NewPath("services").Next(service)), string(path))
+}
+
+func hasPlacement(s types.ServiceConfig) bool {
+	if s.Deploy == nil {
+		return false
+	}
+	p := s.Deploy.Placement
+	return len(p.Constraints) > 0 || len(p.Preferences) > 0 || p.MaxReplicas > 0
+}
+
+// containerNameWithReplicas reports services with a fixed container name which are scaled
+// beyond a single container, as container names must be unique
+func containerNameWithReplicas(project *types.Project) []Finding {
+	var findings []Finding
+	for _, name := range project.ServiceNames() {
+		service := project.Services[name]
+		if service.ContainerName != "" && service.GetScale() > 1 {
+			findings = append(findings, Incompatible(servicePath(name, "container_name"),
+				"container name must be unique, service can't be scaled beyond a single container"))
+		}
+	}
+	return findings
+}
+
+// externalSecrets reports secrets managed by Swarm, which a standalone engine can't access
+func externalSecrets(project *types.Project) []Finding {
+	var findings []Finding
+	for name, secret := range project.Secrets {
+		if secret.External {
+			findings = append(findings, Unsupported(tree.NewPath("secrets").Next(name).Next("external"),
+				"external secrets are managed by Swarm, use file or environment instead"))
+		}
+	}
+	return findings
+}
+
+// swarmFileObjects reports configs and secrets Swarm can't create, as content is only read from files
+func swarmFileObjects(project *types.Project) []Finding {
+	var findings []Finding
+	for name, secret := range project.Secrets {
+		if secret.Environment != "" {
+			findings = append(findings, Unsupported(tree.NewPath("secrets").Next(name).Next("environment"),
+				"stack deploy only creates secrets from files"))
+		}
+	}
+	for name, config := range project.Configs {
+		if config.Environment != "" {
+			findings = append(findings, Unsupported(tree.NewPath("configs").Next(name).Next("environment"),
+				"stack deploy only creates configs from files"))
+		}
+		if config.Content != "" {
+			findings = append(findings, Unsupported(tree.NewPath("configs").Next(name).Next("content"),
+				"stack deploy only creates configs from files"))
+		}
+	}
+	return findings
+}
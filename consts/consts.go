@@ -22,6 +22,8 @@ const (
 	ComposeFilePath              = "COMPOSE_FILE"
 	ComposeDisableDefaultEnvFile = "COMPOSE_DISABLE_ENV_FILE"
 	ComposeProfiles              = "COMPOSE_PROFILES"
+	ComposeAgeKey                = "COMPOSE_AGE_KEY"
+	ComposeAgeKeyFile            = "COMPOSE_AGE_KEY_FILE"
 )
 
 const Extensions = "#extensions" // Using # prefix, we prevent risk to conflict with an actual yaml key
@@ -0,0 +1,301 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dockerfile
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/buildcontext"
+	"github.com/compose-spec/compose-go/v2/template"
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/compose-spec/compose-go/v2/utils"
+)
+
+// Severity qualifies the impact of an Issue on the build
+type Severity string
+
+const (
+	// SeverityError is set for issues which make the build fail
+	SeverityError Severity = "error"
+	// SeverityWarning is set for issues which likely are a misconfiguration
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is an inconsistency between a service build section and the Dockerfile
+type Issue struct {
+	Severity Severity
+	Message  string
+	// Line is the Dockerfile line the issue relates to, if any
+	Line int
+}
+
+func (i Issue) String() string {
+	if i.Line > 0 {
+		return fmt.Sprintf("%s: line %d: %s", i.Severity, i.Line, i.Message)
+	}
+	return fmt.Sprintf("%s: %s", i.Severity, i.Message)
+}
+
+// predefinedArgs can be set by build.args without being declared by an ARG instruction
+var predefinedArgs = []string{
+	"HTTP_PROXY", "http_proxy", "HTTPS_PROXY", "https_proxy", "FTP_PROXY", "ftp_proxy",
+	"NO_PROXY", "no_proxy", "ALL_PROXY", "all_proxy", "SOURCE_DATE_EPOCH",
+}
+
+// automaticArgs are set by the builder
+var automaticArgs = []string{
+	"TARGETPLATFORM", "TARGETOS", "TARGETARCH", "TARGETVARIANT",
+	"BUILDPLATFORM", "BUILDOS", "BUILDARCH", "BUILDVARIANT", "TARGETSTAGE",
+}
+
+// Load parses the Dockerfile used to build a service, from dockerfile_inline or the local build context.
+// It returns nil when the Dockerfile is part of a remote build context. Errors are prefixed by service name
+func Load(project *types.Project, service types.ServiceConfig) (*Dockerfile, error) {
+	build := service.Build
+	if build == nil {
+		return nil, fmt.Errorf("service %q has no build section", service.Name)
+	}
+	if build.DockerfileInline != "" {
+		d, err := Parse(strings.NewReader(build.DockerfileInline))
+		if err != nil {
+			return nil, fmt.Errorf("service %q: dockerfile_inline: %w", service.Name, err)
+		}
+		return d, nil
+	}
+	dir, ok := buildcontext.LocalDir(project.WorkingDir, build.Context)
+	if !ok {
+		return nil, nil
+	}
+	filename := buildcontext.Dockerfile(dir, build)
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("service %q: %w", service.Name, err)
+	}
+	defer f.Close()
+	d, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("service %q: %s: %w", service.Name, filename, err)
+	}
+	return d, nil
+}
+
+// Check validates a service build section against the Dockerfile: build target, args, secrets and additional
+// contexts. Services with a remote build context are not checked
+func Check(project *types.Project, service types.ServiceConfig) ([]Issue, error) {
+	d, err := Load(project, service)
+	var pathErr *fs.PathError
+	if errors.As(err, &pathErr) && errors.Is(err, fs.ErrNotExist) {
+		return []Issue{{
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("Dockerfile %s not found", pathErr.Path),
+		}}, nil
+	}
+	if err != nil || d == nil {
+		return nil, err
+	}
+	return d.Check(service.Build), nil
+}
+
+// Check validates a build section against the Dockerfile
+func (d *Dockerfile) Check(build *types.BuildConfig) []Issue {
+	var issues []Issue
+	target := len(d.Stages) - 1
+	if build.Target != "" {
+		target = slices.IndexFunc(d.Stages, func(s Stage) bool {
+			return s.Name == strings.ToLower(build.Target)
+		})
+		if target < 0 {
+			return append(issues, Issue{
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("target stage %q not found in Dockerfile", build.Target),
+			})
+		}
+	}
+	stages := d.requiredStages(target)
+
+	declared := utils.NewSet[string]()
+	for _, a := range d.Args {
+		declared.Add(a.Name)
+	}
+	for _, s := range d.Stages {
+		for _, a := range s.Args {
+			declared.Add(a.Name)
+		}
+	}
+	for _, name := range utils.MapKeys(build.Args) {
+		if !declared.Has(name) && !slices.Contains(predefinedArgs, name) && !strings.HasPrefix(name, "BUILDKIT_") {
+			issues = append(issues, Issue{
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("build arg %q is not declared by any ARG instruction", name),
+			})
+		}
+	}
+
+	var args []Arg
+	// global args are only used by FROM instructions
+	used := fromVariables(stages)
+	for _, a := range d.Args {
+		if _, ok := used[a.Name]; ok {
+			args = append(args, a)
+		}
+	}
+	for _, s := range stages {
+		args = append(args, s.Args...)
+	}
+	for _, a := range args {
+		if _, ok := build.Args[a.Name]; ok || a.Default != nil || slices.Contains(automaticArgs, a.Name) {
+			continue
+		}
+		if slices.ContainsFunc(d.Args, func(g Arg) bool { return g.Name == a.Name && g.Default != nil }) {
+			// inherits global default value
+			continue
+		}
+		issues = append(issues, Issue{
+			Severity: SeverityWarning,
+			Line:     a.Line,
+			Message:  fmt.Sprintf("ARG %q has no default value and is not set by build.args", a.Name),
+		})
+	}
+
+	secrets := utils.NewSet[string]()
+	for _, s := range build.Secrets {
+		id := s.Target
+		if id == "" {
+			id = s.Source
+		}
+		secrets.Add(id)
+	}
+	mounted := utils.NewSet[string]()
+	for _, s := range d.Stages {
+		for _, m := range s.Secrets {
+			mounted.Add(m.ID)
+		}
+	}
+	for _, s := range stages {
+		for _, m := range s.Secrets {
+			if secrets.Has(m.ID) {
+				continue
+			}
+			severity := SeverityWarning
+			if m.Required {
+				severity = SeverityError
+			}
+			issues = append(issues, Issue{
+				Severity: severity,
+				Line:     m.Line,
+				Message:  fmt.Sprintf("secret %q is mounted but not declared by build.secrets", m.ID),
+			})
+		}
+	}
+	for _, id := range utils.MapKeys(secrets) {
+		if !mounted.Has(id) {
+			issues = append(issues, Issue{
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("build secret %q is not mounted by any RUN instruction", id),
+			})
+		}
+	}
+
+	referenced := utils.NewSet[string]()
+	for _, s := range d.Stages {
+		referenced.Add(d.expand(s.Base, build.Args))
+		referenced.AddAll(s.From...)
+	}
+	for _, name := range utils.MapKeys(build.AdditionalContexts) {
+		if !referenced.Has(name) {
+			issues = append(issues, Issue{
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("additional context %q is not referenced by FROM or COPY --from", name),
+			})
+		}
+	}
+	return issues
+}
+
+// expand resolves variables in a FROM instruction from build args, then global ARG defaults
+func (d *Dockerfile) expand(s string, args types.MappingWithEquals) string {
+	expanded, err := template.SubstituteWithOptions(s, func(name string) (string, bool) {
+		if v, ok := args[name]; ok && v != nil {
+			return *v, true
+		}
+		for _, a := range slices.Backward(d.Args) {
+			if a.Name == name && a.Default != nil {
+				return *a.Default, true
+			}
+		}
+		return "", false
+	}, template.WithoutLogging)
+	if err != nil {
+		return s
+	}
+	return expanded
+}
+
+// requiredStages returns the stages built to produce target stage
+func (d *Dockerfile) requiredStages(target int) []Stage {
+	required := map[int]bool{}
+	var visit func(i int)
+	visit = func(i int) {
+		if required[i] {
+			return
+		}
+		required[i] = true
+		s := d.Stages[i]
+		for _, ref := range append([]string{s.Base}, s.From...) {
+			if dep := d.stageIndex(ref, i); dep >= 0 {
+				visit(dep)
+			}
+		}
+	}
+	visit(target)
+	var stages []Stage
+	for i, s := range d.Stages {
+		if required[i] {
+			stages = append(stages, s)
+		}
+	}
+	return stages
+}
+
+// stageIndex resolves a reference to a stage declared before stage at index before, by name or index
+func (d *Dockerfile) stageIndex(ref string, before int) int {
+	if i, err := strconv.Atoi(ref); err == nil && i >= 0 && i < before {
+		return i
+	}
+	ref = strings.ToLower(ref)
+	for i := 0; i < before; i++ {
+		if d.Stages[i].Name != "" && d.Stages[i].Name == ref {
+			return i
+		}
+	}
+	return -1
+}
+
+// fromVariables returns the variables used by FROM instructions of stages
+func fromVariables(stages []Stage) map[string]template.Variable {
+	from := map[string]any{}
+	for i, s := range stages {
+		from[strconv.Itoa(i)] = s.Base + " " + s.Platform
+	}
+	return template.ExtractVariables(from, nil)
+}
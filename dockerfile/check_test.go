@@ -0,0 +1,152 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dockerfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func mustParse(t *testing.T, content string) *Dockerfile {
+	t.Helper()
+	d, err := Parse(strings.NewReader(content))
+	assert.NilError(t, err)
+	return d
+}
+
+func TestCheckTarget(t *testing.T) {
+	d := mustParse(t, "FROM alpine AS base\nFROM base AS prod\n")
+	assert.Equal(t, len(d.Check(&types.BuildConfig{Target: "Prod"})), 0)
+	assert.DeepEqual(t, d.Check(&types.BuildConfig{Target: "dev"}), []Issue{{
+		Severity: SeverityError,
+		Message:  `target stage "dev" not found in Dockerfile`,
+	}})
+}
+
+func TestCheckArgs(t *testing.T) {
+	d := mustParse(t, `ARG BASE
+ARG UNUSED
+ARG PLATFORM
+ARG BASEIMAGE
+FROM --platform=$PLATFORM ${BASE:-alpine} AS build
+ARG VERSION
+ARG TARGETARCH
+FROM alpine AS dev
+ARG DEBUG
+FROM build
+`)
+	value := "1.0"
+	issues := d.Check(&types.BuildConfig{
+		Args: types.MappingWithEquals{
+			"VERSION":               &value,
+			"HTTP_PROXY":            &value,
+			"BUILDKIT_INLINE_CACHE": &value,
+			"UNDECLARED":            &value,
+		},
+	})
+	assert.DeepEqual(t, issues, []Issue{
+		{Severity: SeverityWarning, Message: `build arg "UNDECLARED" is not declared by any ARG instruction`},
+		{Severity: SeverityWarning, Line: 1, Message: `ARG "BASE" has no default value and is not set by build.args`},
+		{Severity: SeverityWarning, Line: 3, Message: `ARG "PLATFORM" has no default value and is not set by build.args`},
+	})
+}
+
+func TestCheckSecrets(t *testing.T) {
+	d := mustParse(t, `FROM alpine AS build
+RUN --mount=type=secret,id=token,required=true cat /run/secrets/token
+RUN --mount=type=secret,id=netrc cat /run/secrets/netrc
+FROM alpine AS dev
+RUN --mount=type=secret,id=debug true
+FROM build
+`)
+	issues := d.Check(&types.BuildConfig{
+		Secrets: []types.ServiceSecretConfig{
+			{Source: "netrc"},
+			{Source: "aws", Target: "debug"},
+			{Source: "unused"},
+		},
+	})
+	assert.DeepEqual(t, issues, []Issue{
+		{Severity: SeverityError, Line: 2, Message: `secret "token" is mounted but not declared by build.secrets`},
+		{Severity: SeverityWarning, Message: `build secret "unused" is not mounted by any RUN instruction`},
+	})
+}
+
+func TestCheckAdditionalContexts(t *testing.T) {
+	d := mustParse(t, "FROM base\nCOPY --from=assets /src /dst\n")
+	issues := d.Check(&types.BuildConfig{
+		AdditionalContexts: types.Mapping{
+			"base":   "docker-image://alpine",
+			"assets": "./assets",
+			"unused": "./unused",
+		},
+	})
+	assert.DeepEqual(t, issues, []Issue{
+		{Severity: SeverityWarning, Message: `additional context "unused" is not referenced by FROM or COPY --from`},
+	})
+}
+
+func TestCheckAdditionalContextsFromVariable(t *testing.T) {
+	d := mustParse(t, "ARG BASE=base\nARG RUNTIME\nFROM ${BASE} AS build\nFROM $RUNTIME\n")
+	value := "runtime"
+	issues := d.Check(&types.BuildConfig{
+		Args: types.MappingWithEquals{"RUNTIME": &value},
+		AdditionalContexts: types.Mapping{
+			"base":    "docker-image://alpine",
+			"runtime": "docker-image://distroless",
+			"unused":  "./unused",
+		},
+	})
+	assert.DeepEqual(t, issues, []Issue{
+		{Severity: SeverityWarning, Message: `additional context "unused" is not referenced by FROM or COPY --from`},
+	})
+}
+
+func TestCheckService(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "app.Dockerfile"), []byte("FROM alpine AS prod\n"), 0o600))
+	project := &types.Project{
+		WorkingDir: dir,
+		Services: types.Services{
+			"app": {Name: "app", Build: &types.BuildConfig{Context: ".", Dockerfile: "app.Dockerfile", Target: "dev"}},
+			"inline": {Name: "inline", Build: &types.BuildConfig{
+				Context:          ".",
+				DockerfileInline: "FROM alpine\nARG VERSION\n",
+			}},
+			"remote": {Name: "remote", Build: &types.BuildConfig{Context: "https://github.com/docker/compose.git", Target: "dev"}},
+		},
+	}
+
+	issues, err := Check(project, project.Services["app"])
+	assert.NilError(t, err)
+	assert.Equal(t, len(issues), 1)
+	assert.Equal(t, issues[0].String(), `error: target stage "dev" not found in Dockerfile`)
+
+	issues, err = Check(project, project.Services["inline"])
+	assert.NilError(t, err)
+	assert.Equal(t, len(issues), 1)
+	assert.Equal(t, issues[0].String(), `warning: line 2: ARG "VERSION" has no default value and is not set by build.args`)
+
+	issues, err = Check(project, project.Services["remote"])
+	assert.NilError(t, err)
+	assert.Equal(t, len(issues), 0)
+}
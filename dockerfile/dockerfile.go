@@ -0,0 +1,326 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package dockerfile parses the Dockerfile instructions relevant to validate a compose build section
+package dockerfile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Dockerfile is the parsed structure of a Dockerfile
+type Dockerfile struct {
+	// Args are the global ARG instructions, declared before the first FROM
+	Args   []Arg
+	Stages []Stage
+}
+
+// Stage is a build stage, started by a FROM instruction
+type Stage struct {
+	// Name is the stage name set by `AS`, lower-cased as stage names are case-insensitive
+	Name string
+	// Base is the image or stage the stage is based on
+	Base     string
+	Platform string
+	Line     int
+	Args     []Arg
+	// From lists the stages or contexts referenced by COPY --from, ADD --from and RUN --mount from=
+	From    []string
+	Secrets []SecretMount
+}
+
+// Arg is an ARG instruction
+type Arg struct {
+	Name    string
+	Default *string
+	Line    int
+}
+
+// SecretMount is a RUN --mount=type=secret option
+type SecretMount struct {
+	ID       string
+	Required bool
+	Line     int
+}
+
+type instruction struct {
+	command string
+	args    string
+	line    int
+}
+
+var heredocPattern = regexp.MustCompile(`<<-?\s*["']?([A-Za-z_][A-Za-z0-9_]*)["']?`)
+
+// Parse reads Dockerfile instructions. Only FROM, ARG, COPY, ADD and RUN instructions are interpreted,
+// other instructions are validated for syntax but ignored
+func Parse(r io.Reader) (*Dockerfile, error) {
+	instructions, err := readInstructions(r)
+	if err != nil {
+		return nil, err
+	}
+	d := &Dockerfile{}
+	var stage *Stage
+	for _, i := range instructions {
+		switch i.command {
+		case "from":
+			s, err := parseFrom(i)
+			if err != nil {
+				return nil, err
+			}
+			d.Stages = append(d.Stages, s)
+			stage = &d.Stages[len(d.Stages)-1]
+		case "arg":
+			args, err := parseArgs(i)
+			if err != nil {
+				return nil, err
+			}
+			if stage == nil {
+				d.Args = append(d.Args, args...)
+			} else {
+				stage.Args = append(stage.Args, args...)
+			}
+		case "copy", "add", "run":
+			if stage == nil {
+				return nil, fmt.Errorf("line %d: %s instruction before FROM", i.line, strings.ToUpper(i.command))
+			}
+			flags, _ := splitFlags(i.args)
+			for _, f := range flags {
+				name, value, _ := strings.Cut(f, "=")
+				switch {
+				case name == "from" && i.command != "run":
+					stage.From = append(stage.From, value)
+				case name == "mount" && i.command == "run":
+					mount := parseCSV(value)
+					if from := mount["from"]; from != "" {
+						stage.From = append(stage.From, from)
+					}
+					if mount["type"] == "secret" {
+						id := mount["id"]
+						if id == "" {
+							if mount["target"] == "" {
+								return nil, fmt.Errorf("line %d: secret mount requires an id or a target", i.line)
+							}
+							id = path.Base(mount["target"])
+						}
+						required, set := mount["required"]
+						stage.Secrets = append(stage.Secrets, SecretMount{
+							ID:       id,
+							Required: set && (required == "" || required == "true"),
+							Line:     i.line,
+						})
+					}
+				}
+			}
+		}
+	}
+	if len(d.Stages) == 0 {
+		return nil, fmt.Errorf("no FROM instruction")
+	}
+	return d, nil
+}
+
+// Stage returns the stage with name, matched case-insensitively
+func (d *Dockerfile) Stage(name string) (Stage, bool) {
+	name = strings.ToLower(name)
+	for _, s := range d.Stages {
+		if s.Name != "" && s.Name == name {
+			return s, true
+		}
+	}
+	return Stage{}, false
+}
+
+// readInstructions reads Dockerfile lines, handling parser directives, comments, line continuations and heredocs
+func readInstructions(r io.Reader) ([]instruction, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	escape := '\\'
+	directives := true
+	var (
+		instructions []instruction
+		current      strings.Builder
+		start        int
+		lineNumber   int
+		heredocs     []string
+	)
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		if len(heredocs) > 0 {
+			// heredoc content is part of the instruction but not interpreted
+			if strings.TrimLeft(line, "\t") == heredocs[0] {
+				heredocs = heredocs[1:]
+			}
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		if directives {
+			if d, ok := strings.CutPrefix(trimmed, "#"); ok {
+				key, value, found := strings.Cut(d, "=")
+				if found && strings.EqualFold(strings.TrimSpace(key), "escape") {
+					value = strings.TrimSpace(value)
+					if value != "\\" && value != "`" {
+						return nil, fmt.Errorf("line %d: invalid escape token %q", lineNumber, value)
+					}
+					escape = rune(value[0])
+				}
+				if found {
+					continue
+				}
+			}
+			directives = false
+		}
+		if current.Len() == 0 && (trimmed == "" || strings.HasPrefix(trimmed, "#")) {
+			continue
+		}
+		if current.Len() > 0 && strings.HasPrefix(trimmed, "#") {
+			// comments are allowed within continuation lines
+			continue
+		}
+		if current.Len() == 0 {
+			start = lineNumber
+		}
+		if strings.HasSuffix(trimmed, string(escape)) {
+			current.WriteString(strings.TrimSuffix(trimmed, string(escape)))
+			current.WriteString(" ")
+			continue
+		}
+		current.WriteString(trimmed)
+		text := current.String()
+		current.Reset()
+
+		command, args, _ := strings.Cut(text, " ")
+		i := instruction{command: strings.ToLower(command), args: strings.TrimSpace(args), line: start}
+		if i.command == "run" || i.command == "copy" || i.command == "add" {
+			for _, m := range heredocPattern.FindAllStringSubmatch(i.args, -1) {
+				heredocs = append(heredocs, m[1])
+			}
+		}
+		instructions = append(instructions, i)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if current.Len() > 0 {
+		return nil, fmt.Errorf("line %d: unterminated line continuation", start)
+	}
+	return instructions, nil
+}
+
+func parseFrom(i instruction) (Stage, error) {
+	flags, args := splitFlags(i.args)
+	s := Stage{Line: i.line}
+	for _, f := range flags {
+		if name, value, _ := strings.Cut(f, "="); name == "platform" {
+			s.Platform = value
+		}
+	}
+	fields := strings.Fields(args)
+	switch {
+	case len(fields) == 1:
+	case len(fields) == 3 && strings.EqualFold(fields[1], "as"):
+		s.Name = strings.ToLower(fields[2])
+	default:
+		return Stage{}, fmt.Errorf("line %d: FROM requires either one or three arguments", i.line)
+	}
+	s.Base = fields[0]
+	return s, nil
+}
+
+func parseArgs(i instruction) ([]Arg, error) {
+	words := splitWords(i.args)
+	if len(words) == 0 {
+		return nil, fmt.Errorf("line %d: ARG requires at least one argument", i.line)
+	}
+	args := make([]Arg, 0, len(words))
+	for _, w := range words {
+		name, value, hasDefault := strings.Cut(w, "=")
+		a := Arg{Name: name, Line: i.line}
+		if hasDefault {
+			value = unquote(value)
+			a.Default = &value
+		}
+		args = append(args, a)
+	}
+	return args, nil
+}
+
+// splitFlags separates the leading `--name=value` flags of an instruction from its arguments
+func splitFlags(s string) ([]string, string) {
+	var flags []string
+	for {
+		s = strings.TrimSpace(s)
+		if !strings.HasPrefix(s, "--") {
+			return flags, s
+		}
+		flag, rest, _ := strings.Cut(s, " ")
+		flags = append(flags, strings.TrimPrefix(flag, "--"))
+		s = rest
+	}
+}
+
+// splitWords splits on whitespace, keeping quoted strings together
+func splitWords(s string) []string {
+	var (
+		words []string
+		word  strings.Builder
+		quote rune
+	)
+	for _, c := range s {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+			word.WriteRune(c)
+		case c == '"' || c == '\'':
+			quote = c
+			word.WriteRune(c)
+		case c == ' ' || c == '\t':
+			if word.Len() > 0 {
+				words = append(words, word.String())
+				word.Reset()
+			}
+		default:
+			word.WriteRune(c)
+		}
+	}
+	if word.Len() > 0 {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+func parseCSV(s string) map[string]string {
+	res := map[string]string{}
+	for _, field := range strings.Split(s, ",") {
+		key, value, _ := strings.Cut(field, "=")
+		res[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+	}
+	return res
+}
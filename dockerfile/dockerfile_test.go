@@ -0,0 +1,102 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dockerfile
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+const multiStage = `# syntax=docker/dockerfile:1
+ARG GO_VERSION=1.24
+ARG BASE
+
+FROM --platform=$BUILDPLATFORM golang:${GO_VERSION} AS Build
+ARG VERSION
+ARG LDFLAGS="-s -w"
+RUN --mount=type=secret,id=netrc,required \
+    # a comment
+    --mount=type=cache,target=/root/.cache \
+    go build -ldflags "$LDFLAGS" ./...
+
+FROM build AS test
+RUN --mount=type=secret,target=/run/secrets/token go test ./...
+
+FROM ${BASE}
+COPY --from=build /out/app /app
+COPY --from=assets /img /img
+RUN <<SCRIPT
+FROM this is not an instruction
+SCRIPT
+ADD <<EOT /etc/motd
+FROM neither is this
+EOT
+`
+
+func TestParse(t *testing.T) {
+	d, err := Parse(strings.NewReader(multiStage))
+	assert.NilError(t, err)
+
+	ldflags, goVersion := "-s -w", "1.24"
+	assert.DeepEqual(t, d.Args, []Arg{
+		{Name: "GO_VERSION", Default: &goVersion, Line: 2},
+		{Name: "BASE", Line: 3},
+	})
+	assert.Equal(t, len(d.Stages), 3)
+	assert.DeepEqual(t, d.Stages[0], Stage{
+		Name:     "build",
+		Base:     "golang:${GO_VERSION}",
+		Platform: "$BUILDPLATFORM",
+		Line:     5,
+		Args: []Arg{
+			{Name: "VERSION", Line: 6},
+			{Name: "LDFLAGS", Default: &ldflags, Line: 7},
+		},
+		Secrets: []SecretMount{{ID: "netrc", Required: true, Line: 8}},
+	})
+	assert.DeepEqual(t, d.Stages[1].Secrets, []SecretMount{{ID: "token", Line: 14}})
+	assert.DeepEqual(t, d.Stages[2].From, []string{"build", "assets"})
+
+	s, ok := d.Stage("BUILD")
+	assert.Assert(t, ok)
+	assert.Equal(t, s.Line, 5)
+	_, ok = d.Stage("missing")
+	assert.Assert(t, !ok)
+}
+
+func TestParseErrors(t *testing.T) {
+	for content, expected := range map[string]string{
+		"RUN echo":                                          "line 1: RUN instruction before FROM",
+		"FROM alpine AS":                                    "line 1: FROM requires either one or three arguments",
+		"FROM alpine\nRUN echo \\":                          "line 2: unterminated line continuation",
+		"# escape=x\nFROM alpine":                           `line 1: invalid escape token "x"`,
+		"# just a comment\nLABEL a=b\n":                     "no FROM instruction",
+		"FROM alpine\nRUN --mount=type=secret,required cat": "line 2: secret mount requires an id or a target",
+	} {
+		_, err := Parse(strings.NewReader(content))
+		assert.Error(t, err, expected, content)
+	}
+}
+
+func TestParseEscapeDirective(t *testing.T) {
+	d, err := Parse(strings.NewReader("# escape=`\nFROM mcr.microsoft.com/windows AS base\nRUN dir c:\\ `\n  && echo done\nFROM base\n"))
+	assert.NilError(t, err)
+	assert.Equal(t, len(d.Stages), 2)
+	assert.Equal(t, d.Stages[1].Base, "base")
+}
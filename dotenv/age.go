@@ -0,0 +1,128 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dotenv
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+	"github.com/compose-spec/compose-go/v2/consts"
+)
+
+// Age is the format for env files encrypted with age (https://age-encryption.org).
+// The decrypted content is parsed as a `.env` file
+const Age = "age"
+
+// AgeParser returns a Parser for env files encrypted with age, using identities to decrypt.
+// When no identity is set, identities are parsed from COMPOSE_AGE_KEY, or read from the
+// COMPOSE_AGE_KEY_FILE file, as resolved by the lookup function
+func AgeParser(identities ...age.Identity) Parser {
+	return func(r io.Reader, filename string, vars map[string]string, lookup func(key string) (string, bool)) error {
+		ids := identities
+		if len(ids) == 0 {
+			var err error
+			ids, err = ageIdentitiesFromLookup(lookup)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", filename, err)
+			}
+		}
+		decrypted, err := decryptAge(r, ids)
+		if err != nil {
+			return fmt.Errorf("failed to decrypt %s: %w", filename, err)
+		}
+		err = parseWithLookup(decrypted, vars, lookup)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", filename, err)
+		}
+		return nil
+	}
+}
+
+func ageIdentitiesFromLookup(lookup func(key string) (string, bool)) ([]age.Identity, error) {
+	if lookup == nil {
+		lookup = noLookupFn
+	}
+	if key, ok := lookup(consts.ComposeAgeKey); ok && key != "" {
+		return age.ParseIdentities(strings.NewReader(key))
+	}
+	if file, ok := lookup(consts.ComposeAgeKeyFile); ok && file != "" {
+		return ReadAgeIdentities(file)
+	}
+	return nil, fmt.Errorf("no age identity to decrypt, set %s or %s", consts.ComposeAgeKey, consts.ComposeAgeKeyFile)
+}
+
+// ReadAgeIdentities reads age identities from a key file, as generated by age-keygen
+func ReadAgeIdentities(filename string) ([]age.Identity, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return age.ParseIdentities(f)
+}
+
+// decryptAge decrypts either binary or armored age encrypted content
+func decryptAge(r io.Reader, identities []age.Identity) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	if start, _ := br.Peek(len(armor.Header)); string(start) == armor.Header {
+		r = armor.NewReader(br)
+	} else {
+		r = br
+	}
+	return age.Decrypt(r, identities...)
+}
+
+// EncryptAge encrypts an env file for recipients, as an armored age file
+func EncryptAge(w io.Writer, r io.Reader, recipients ...age.Recipient) error {
+	if len(recipients) == 0 {
+		return errors.New("no age recipient to encrypt for")
+	}
+	aw := armor.NewWriter(w)
+	ew, err := age.Encrypt(aw, recipients...)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(ew, r); err != nil {
+		return err
+	}
+	if err := ew.Close(); err != nil {
+		return err
+	}
+	return aw.Close()
+}
+
+// ReencryptAge decrypts an age encrypted env file using identities, then encrypts it for recipients.
+// As age doesn't support adding a recipient to an encrypted file, recipients must list both existing and new ones
+func ReencryptAge(w io.Writer, r io.Reader, identities []age.Identity, recipients ...age.Recipient) error {
+	decrypted, err := decryptAge(r, identities)
+	if err != nil {
+		return err
+	}
+	// decrypt fully before writing, so w can safely target the same file as r
+	plain, err := io.ReadAll(decrypted)
+	if err != nil {
+		return err
+	}
+	return EncryptAge(w, bytes.NewReader(plain), recipients...)
+}
@@ -0,0 +1,68 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dotenv
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/compose-spec/compose-go/v2/consts"
+	"gotest.tools/v3/assert"
+)
+
+func TestAgeFormat(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	assert.NilError(t, err)
+
+	var encrypted bytes.Buffer
+	err = EncryptAge(&encrypted, strings.NewReader("FOO=bar\nZOT=${QIX}\n"), identity.Recipient())
+	assert.NilError(t, err)
+	assert.Check(t, strings.HasPrefix(encrypted.String(), "-----BEGIN AGE ENCRYPTED FILE-----"))
+
+	lookup := func(k string) (string, bool) {
+		switch k {
+		case consts.ComposeAgeKey:
+			return identity.String(), true
+		case "QIX":
+			return "qix", true
+		}
+		return "", false
+	}
+	env := map[string]string{}
+	err = ParseWithFormat(bytes.NewReader(encrypted.Bytes()), "test.env.age", env, lookup, Age)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, map[string]string{"FOO": "bar", "ZOT": "qix"}, env)
+	assert.Check(t, IsSensitiveFormat(Age))
+
+	err = ParseWithFormat(bytes.NewReader(encrypted.Bytes()), "test.env.age", env, nil, Age)
+	assert.ErrorContains(t, err, "no age identity to decrypt")
+
+	other, err := age.GenerateX25519Identity()
+	assert.NilError(t, err)
+	err = AgeParser(other)(bytes.NewReader(encrypted.Bytes()), "test.env.age", env, nil)
+	assert.ErrorContains(t, err, "failed to decrypt test.env.age")
+
+	var reencrypted bytes.Buffer
+	err = ReencryptAge(&reencrypted, &encrypted, []age.Identity{identity}, identity.Recipient(), other.Recipient())
+	assert.NilError(t, err)
+	env = map[string]string{}
+	err = AgeParser(other)(bytes.NewReader(reencrypted.Bytes()), "test.env.age", env, nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, map[string]string{"FOO": "bar", "ZOT": ""}, env)
+}
@@ -0,0 +1,279 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dotenv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// Document is an editable env file which preserves comments, blank lines, `export` prefixes,
+// quoting style and ordering of entries, so it can be written back with minimal changes
+type Document struct {
+	bom   bool
+	nodes []*documentNode
+}
+
+// documentNode is either an entry (key is set) or raw text (comments, blank lines) between entries.
+// An entry is rendered as prefix + value + suffix, where value is the raw (quoted, escaped) value
+type documentNode struct {
+	key       string
+	prefix    string
+	value     string
+	suffix    string
+	inherited bool
+}
+
+func (n *documentNode) String() string {
+	return n.prefix + n.value + n.suffix
+}
+
+// ParseDocument reads an env file from io.Reader as an editable Document
+func ParseDocument(r io.Reader) (*Document, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	d := &Document{
+		bom: bytes.HasPrefix(data, utf8BOM),
+	}
+	src := string(bytes.TrimPrefix(data, utf8BOM))
+
+	p := newParser()
+	vars := map[string]string{}
+	cutset := src
+	for {
+		start := p.getStatementStart(cutset)
+		d.appendRaw(cutset[:len(cutset)-len(start)])
+		if start == "" {
+			break
+		}
+
+		key, left, inherited, err := p.locateKeyName(start)
+		if err != nil {
+			return nil, err
+		}
+		if strings.Contains(key, " ") {
+			return nil, fmt.Errorf("line %d: key cannot contain a space", p.line)
+		}
+		n := &documentNode{
+			key:       key,
+			prefix:    start[:len(start)-len(left)],
+			inherited: inherited,
+		}
+		if inherited {
+			cutset = left
+			d.nodes = append(d.nodes, n)
+			continue
+		}
+
+		_, rest, err := p.extractVarValue(left, vars, noLookupFn)
+		if err != nil {
+			return nil, err
+		}
+		n.value = left[:len(left)-len(rest)]
+		if _, quoted := hasQuotePrefix(left); quoted {
+			n.suffix, rest = p.splitLineEnd(rest)
+		} else {
+			n.value, n.suffix = splitUnquotedValue(n.value)
+		}
+		d.nodes = append(d.nodes, n)
+		cutset = rest
+	}
+	return d, nil
+}
+
+// splitUnquotedValue splits a raw unquoted value line into value and trailing spaces, inline comment and line break
+func splitUnquotedValue(line string) (string, string) {
+	value, _, _ := strings.Cut(line, "\n")
+	value, _, _ = strings.Cut(value, " #")
+	value = strings.TrimRightFunc(value, unicode.IsSpace)
+	return value, line[len(value):]
+}
+
+// splitLineEnd splits trailing spaces, inline comment and line break following a quoted value
+func (p *parser) splitLineEnd(rest string) (string, string) {
+	line, _, found := strings.Cut(rest, "\n")
+	trimmed := strings.TrimLeftFunc(line, isSpace)
+	if trimmed != "" && trimmed[0] != charComment {
+		return "", rest
+	}
+	if found {
+		p.line++
+		line += "\n"
+	}
+	return line, rest[len(line):]
+}
+
+func (d *Document) appendRaw(text string) {
+	if text == "" {
+		return
+	}
+	d.nodes = append(d.nodes, &documentNode{prefix: text})
+}
+
+// Keys returns keys declared by the document, in order of first declaration
+func (d *Document) Keys() []string {
+	var keys []string
+	seen := map[string]bool{}
+	for _, n := range d.nodes {
+		if n.key == "" || seen[n.key] {
+			continue
+		}
+		seen[n.key] = true
+		keys = append(keys, n.key)
+	}
+	return keys
+}
+
+// Get returns the value for key as the env file parser computes it, with variables
+// resolved against the entries declared before it
+func (d *Document) Get(key string) (string, bool) {
+	vars := map[string]string{}
+	if err := newParser().parse(d.body(), vars, nil); err != nil {
+		return "", false
+	}
+	v, ok := vars[key]
+	return v, ok
+}
+
+// Set sets the literal value for key. The last declaration of key is updated in place,
+// keeping its quoting style when possible, otherwise a new entry is appended
+func (d *Document) Set(key string, value string) error {
+	if err := validateDocumentKey(key); err != nil {
+		return err
+	}
+	for i := len(d.nodes) - 1; i >= 0; i-- {
+		n := d.nodes[i]
+		if n.key != key {
+			continue
+		}
+		if n.inherited {
+			n.prefix = strings.TrimRightFunc(n.prefix, unicode.IsSpace) + "="
+			n.suffix = "\n"
+			n.inherited = false
+		}
+		quoted, err := quoteValue(n, value)
+		if err != nil {
+			return err
+		}
+		n.value = quoted
+		return nil
+	}
+
+	if len(d.nodes) > 0 && !strings.HasSuffix(d.nodes[len(d.nodes)-1].String(), "\n") {
+		d.appendRaw("\n")
+	}
+	n := &documentNode{
+		key:    key,
+		prefix: key + "=",
+		suffix: "\n",
+	}
+	quoted, err := quoteValue(n, value)
+	if err != nil {
+		return err
+	}
+	n.value = quoted
+	d.nodes = append(d.nodes, n)
+	return nil
+}
+
+// Delete removes all declarations of key, and reports whether key was declared
+func (d *Document) Delete(key string) bool {
+	found := false
+	nodes := d.nodes[:0]
+	for _, n := range d.nodes {
+		if n.key == key {
+			found = true
+			continue
+		}
+		nodes = append(nodes, n)
+	}
+	d.nodes = nodes
+	return found
+}
+
+// String returns the env file content
+func (d *Document) String() string {
+	if d.bom {
+		return string(utf8BOM) + d.body()
+	}
+	return d.body()
+}
+
+func (d *Document) body() string {
+	var sb strings.Builder
+	for _, n := range d.nodes {
+		sb.WriteString(n.String())
+	}
+	return sb.String()
+}
+
+func validateDocumentKey(key string) error {
+	if key == "" {
+		return errors.New("invalid empty key")
+	}
+	k, _, inherited, err := newParser().locateKeyName(key + "=")
+	if err != nil {
+		return err
+	}
+	if inherited || k != key || strings.Contains(key, " ") {
+		return fmt.Errorf("invalid key %q", key)
+	}
+	return nil
+}
+
+// quoteValue renders value for entry n, preferring the current quoting style of the entry.
+// The parser is used to check rendered value parses back to the exact same value
+func quoteValue(n *documentNode, value string) (string, error) {
+	quote, isQuoted := hasQuotePrefix(n.value)
+	if n.value == "" {
+		// keep unquoted style for new or empty entries when possible
+		isQuoted = false
+	}
+	candidates := []string{quoteDouble(value)}
+	switch {
+	case !isQuoted:
+		candidates = append([]string{strings.ReplaceAll(value, "$", "$$")}, candidates...)
+	case quote == prefixSingleQuote && !strings.ContainsAny(value, `'\`):
+		candidates = append([]string{"'" + value + "'"}, candidates...)
+	}
+	for _, candidate := range candidates {
+		vars := map[string]string{}
+		err := newParser().parse(n.prefix+candidate+n.suffix, vars, nil)
+		if err == nil && vars[n.key] == value {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("value for %s cannot be written as an env file entry", n.key)
+}
+
+var doubleQuoteEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`"`, `\"`,
+	"$", "$$",
+	"\n", `\n`,
+	"\r", `\r`,
+)
+
+func quoteDouble(value string) string {
+	return `"` + doubleQuoteEscaper.Replace(value) + `"`
+}
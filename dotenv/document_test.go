@@ -0,0 +1,109 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dotenv
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	is "gotest.tools/v3/assert/cmp"
+)
+
+const documentInput = `# database settings
+export DB_HOST=localhost # inline comment
+
+DB_USER='admin'
+DB_PASS="s3cr\"et" # keep me
+INHERITED
+LAST=value`
+
+func TestDocumentRoundTrip(t *testing.T) {
+	doc, err := ParseDocument(strings.NewReader(documentInput))
+	assert.NilError(t, err)
+	assert.Equal(t, documentInput, doc.String())
+	assert.DeepEqual(t, []string{"DB_HOST", "DB_USER", "DB_PASS", "INHERITED", "LAST"}, doc.Keys())
+
+	v, ok := doc.Get("DB_PASS")
+	assert.Check(t, ok)
+	assert.Equal(t, `s3cr"et`, v)
+	_, ok = doc.Get("INHERITED")
+	assert.Check(t, !ok)
+}
+
+func TestDocumentSet(t *testing.T) {
+	doc, err := ParseDocument(strings.NewReader(documentInput))
+	assert.NilError(t, err)
+
+	assert.NilError(t, doc.Set("DB_HOST", "db.local"))
+	assert.NilError(t, doc.Set("DB_USER", "root"))
+	assert.NilError(t, doc.Set("DB_PASS", "$ecret"))
+	assert.NilError(t, doc.Set("INHERITED", "now set"))
+	assert.NilError(t, doc.Set("NEW", "it's new"))
+	assert.Equal(t, `# database settings
+export DB_HOST=db.local # inline comment
+
+DB_USER='root'
+DB_PASS="$$ecret" # keep me
+INHERITED=now set
+LAST=value
+NEW=it's new
+`, doc.String())
+
+	// quoting style falls back to double quotes when the value can't be written otherwise
+	assert.NilError(t, doc.Set("DB_USER", "it's"))
+	assert.NilError(t, doc.Set("LAST", " multi\nline # value\\"))
+	assert.NilError(t, doc.Set("NEW", `"quoted"`))
+
+	expected := map[string]string{
+		"DB_HOST":   "db.local",
+		"DB_USER":   "it's",
+		"DB_PASS":   "$ecret",
+		"INHERITED": "now set",
+		"LAST":      " multi\nline # value\\",
+		"NEW":       `"quoted"`,
+	}
+	parsed, err := Parse(strings.NewReader(doc.String()))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, expected, parsed)
+
+	assert.ErrorContains(t, doc.Set("INVALID KEY", "value"), "INVALID KEY")
+	assert.ErrorContains(t, doc.Set("", "value"), "invalid empty key")
+}
+
+func TestDocumentDelete(t *testing.T) {
+	doc, err := ParseDocument(strings.NewReader(documentInput + "\nDB_HOST=override\n"))
+	assert.NilError(t, err)
+
+	assert.Check(t, doc.Delete("DB_HOST"))
+	assert.Check(t, doc.Delete("DB_PASS"))
+	assert.Check(t, !doc.Delete("UNKNOWN"))
+	assert.Check(t, is.Equal(`# database settings
+
+DB_USER='admin'
+INHERITED
+LAST=value
+`, doc.String()))
+}
+
+func TestDocumentBOM(t *testing.T) {
+	input := "\uFEFFFOO=bar\n"
+	doc, err := ParseDocument(strings.NewReader(input))
+	assert.NilError(t, err)
+	assert.NilError(t, doc.Set("FOO", "zot"))
+	assert.Equal(t, "\uFEFFFOO=zot\n", doc.String())
+}
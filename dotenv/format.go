@@ -19,9 +19,16 @@ package dotenv
 import (
 	"fmt"
 	"io"
+	"path/filepath"
+	"strings"
 )
 
-const DotEnv = ".env"
+const (
+	DotEnv     = ".env"
+	JSON       = "json"
+	YAML       = "yaml"
+	Properties = "properties"
+)
 
 var formats = map[string]Parser{
 	DotEnv: func(r io.Reader, filename string, vars map[string]string, lookup func(key string) (string, bool)) error {
@@ -31,6 +38,15 @@ var formats = map[string]Parser{
 		}
 		return nil
 	},
+	JSON:       parseJSON,
+	YAML:       parseYAML,
+	Properties: parseProperties,
+	Age:        AgeParser(),
+}
+
+// sensitiveFormats lists formats for which parsed values must be considered sensitive
+var sensitiveFormats = map[string]bool{
+	Age: true,
 }
 
 type Parser func(r io.Reader, filename string, vars map[string]string, lookup func(key string) (string, bool)) error
@@ -39,6 +55,18 @@ func RegisterFormat(format string, p Parser) {
 	formats[format] = p
 }
 
+// RegisterSensitiveFormat registers a Parser for a format which values must be considered sensitive,
+// typically as read from an encrypted file
+func RegisterSensitiveFormat(format string, p Parser) {
+	formats[format] = p
+	sensitiveFormats[format] = true
+}
+
+// IsSensitiveFormat reports whether values parsed with format must be considered sensitive
+func IsSensitiveFormat(format string) bool {
+	return sensitiveFormats[format]
+}
+
 func ParseWithFormat(r io.Reader, filename string, vars map[string]string, resolve LookupFn, format string) error {
 	if format == "" {
 		format = DotEnv
@@ -49,3 +77,53 @@ func ParseWithFormat(r io.Reader, filename string, vars map[string]string, resol
 	}
 	return fn(r, filename, vars, resolve)
 }
+
+// DetectFormat guesses the env_file format from filename extension, defaulting to DotEnv.
+// ParseWithFormat doesn't rely on it, as files with those extensions have always been parsed as DotEnv
+func DetectFormat(filename string) string {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".json":
+		return JSON
+	case ".yaml", ".yml":
+		return YAML
+	case ".properties":
+		return Properties
+	case ".age":
+		return Age
+	default:
+		return DotEnv
+	}
+}
+
+// flattenedVars collects variables from nested structures, joining nested keys with `_`
+// and detecting conflicts between flattened keys
+type flattenedVars struct {
+	vars map[string]string
+	seen map[string]int
+}
+
+func newFlattenedVars(vars map[string]string) *flattenedVars {
+	return &flattenedVars{
+		vars: vars,
+		seen: map[string]int{},
+	}
+}
+
+func flattenKey(prefix string, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "_" + key
+}
+
+func (f *flattenedVars) set(key string, value string, line int) error {
+	if key == "" {
+		return fmt.Errorf("line %d: empty key", line)
+	}
+	if previous, ok := f.seen[key]; ok {
+		return fmt.Errorf("line %d: key %q conflicts with key declared on line %d", line, key, previous)
+	}
+	f.seen[key] = line
+	f.vars[key] = value
+	return nil
+}
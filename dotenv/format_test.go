@@ -0,0 +1,119 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dotenv
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func parseFixture(t *testing.T, filename string, format string) map[string]string {
+	t.Helper()
+	f, err := os.Open(filename)
+	assert.NilError(t, err)
+	defer f.Close()
+	env := map[string]string{}
+	err = ParseWithFormat(f, filename, env, nil, format)
+	assert.NilError(t, err)
+	return env
+}
+
+func TestParseStructuredFormats(t *testing.T) {
+	expected := map[string]string{
+		"FOO":        "bar",
+		"COUNT":      "1.50",
+		"ENABLED":    "true",
+		"EMPTY":      "",
+		"DB_HOST":    "localhost",
+		"DB_PORTS_0": "5432",
+		"DB_PORTS_1": "5433",
+	}
+	assert.DeepEqual(t, expected, parseFixture(t, "fixtures/nested.json", JSON))
+	assert.DeepEqual(t, expected, parseFixture(t, "fixtures/nested.yaml", YAML))
+	assert.DeepEqual(t, expected, parseFixture(t, "fixtures/nested.json", YAML))
+}
+
+func TestParseProperties(t *testing.T) {
+	expected := map[string]string{
+		"FOO":          "bar",
+		"COUNT":        "1.50",
+		"ENABLED":      "true",
+		"EMPTY":        "",
+		"DB.HOST":      "localhost",
+		"GREETING":     "café\tok",
+		"key=with:sep": "value",
+	}
+	assert.DeepEqual(t, expected, parseFixture(t, "fixtures/app.properties", Properties))
+}
+
+func TestParseFormatErrors(t *testing.T) {
+	testcases := []struct {
+		format   string
+		input    string
+		expected string
+	}{
+		{
+			format:   JSON,
+			input:    "{\n  \"FOO\": \"bar\",\n  \"ZOT\" \"qix\"\n}",
+			expected: "failed to read test: line 3: invalid character '\"' after object key",
+		},
+		{
+			format:   JSON,
+			input:    "[\"FOO\"]",
+			expected: "failed to read test: line 1: top-level value must be an object",
+		},
+		{
+			format:   JSON,
+			input:    "{\n  \"A_B\": 1,\n  \"A\": {\n    \"B\": 2\n  }\n}",
+			expected: `failed to read test: line 4: key "A_B" conflicts with key declared on line 2`,
+		},
+		{
+			format:   YAML,
+			input:    "FOO: bar\n  ZOT: qix\n",
+			expected: "failed to read test: yaml: line 2, column 6: mapping values are not allowed in this context",
+		},
+		{
+			format:   YAML,
+			input:    "A_B: 1\nA:\n  B: 2\n",
+			expected: `failed to read test: line 3: key "A_B" conflicts with key declared on line 1`,
+		},
+		{
+			format:   Properties,
+			input:    "FOO=bar\n=zot\n",
+			expected: "failed to read test: line 2: empty key",
+		},
+		{
+			format:   Properties,
+			input:    "FOO=bar\nZOT=qix\nFOO=baz\n",
+			expected: `failed to read test: line 3: key "FOO" conflicts with key declared on line 1`,
+		},
+		{
+			format:   Properties,
+			input:    "FOO=bar\nZOT=\\u00zz\n",
+			expected: `failed to read test: line 2: malformed \uxxxx encoding`,
+		},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.format, func(t *testing.T) {
+			err := ParseWithFormat(strings.NewReader(tc.input), "test", map[string]string{}, nil, tc.format)
+			assert.Error(t, err, tc.expected)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dotenv
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// parseJSON reads a JSON object as env file. Nested objects and arrays are flattened
+// by joining keys (or array indexes) with `_`, and null is set as an empty string
+func parseJSON(r io.Reader, filename string, vars map[string]string, _ func(key string) (string, bool)) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	data = bytes.TrimPrefix(data, utf8BOM)
+
+	p := &jsonParser{
+		data: data,
+		dec:  json.NewDecoder(bytes.NewReader(data)),
+		out:  newFlattenedVars(vars),
+	}
+	p.dec.UseNumber()
+	if err := p.parse(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	return nil
+}
+
+type jsonParser struct {
+	data []byte
+	dec  *json.Decoder
+	out  *flattenedVars
+}
+
+func (p *jsonParser) parse() error {
+	tok, err := p.token()
+	if err != nil {
+		return err
+	}
+	if tok != json.Delim('{') {
+		return fmt.Errorf("line %d: top-level value must be an object", p.line())
+	}
+	if err := p.object(""); err != nil {
+		return err
+	}
+	if _, err := p.dec.Token(); !errors.Is(err, io.EOF) {
+		return fmt.Errorf("line %d: unexpected data after top-level object", p.line())
+	}
+	return nil
+}
+
+func (p *jsonParser) object(prefix string) error {
+	for p.dec.More() {
+		tok, err := p.token()
+		if err != nil {
+			return err
+		}
+		if err := p.value(flattenKey(prefix, tok.(string))); err != nil {
+			return err
+		}
+	}
+	// consume closing '}'
+	_, err := p.token()
+	return err
+}
+
+func (p *jsonParser) array(prefix string) error {
+	for i := 0; p.dec.More(); i++ {
+		if err := p.value(flattenKey(prefix, strconv.Itoa(i))); err != nil {
+			return err
+		}
+	}
+	// consume closing ']'
+	_, err := p.token()
+	return err
+}
+
+func (p *jsonParser) value(key string) error {
+	tok, err := p.token()
+	if err != nil {
+		return err
+	}
+	line := p.line()
+	switch v := tok.(type) {
+	case json.Delim:
+		if v == '{' {
+			return p.object(key)
+		}
+		return p.array(key)
+	case string:
+		return p.out.set(key, v, line)
+	case json.Number:
+		return p.out.set(key, v.String(), line)
+	case bool:
+		return p.out.set(key, strconv.FormatBool(v), line)
+	default:
+		return p.out.set(key, "", line)
+	}
+}
+
+func (p *jsonParser) token() (json.Token, error) {
+	tok, err := p.dec.Token()
+	if err == nil {
+		return tok, nil
+	}
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return nil, fmt.Errorf("line %d: %w", lineAt(p.data, syntaxErr.Offset), err)
+	}
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("line %d: %w", p.line(), io.ErrUnexpectedEOF)
+	}
+	return nil, fmt.Errorf("line %d: %w", p.line(), err)
+}
+
+// line returns the line number of the current decoder position
+func (p *jsonParser) line() int {
+	return lineAt(p.data, p.dec.InputOffset())
+}
+
+func lineAt(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte{'\n'}) + 1
+}
@@ -0,0 +1,326 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dotenv
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/compose-spec/compose-go/v2/template"
+)
+
+// LintSeverity is the severity of a LintFinding
+type LintSeverity string
+
+const (
+	LintError   LintSeverity = "error"
+	LintWarning LintSeverity = "warning"
+)
+
+// Rules reported by Lint
+const (
+	LintRuleSyntax              = "syntax"
+	LintRuleDuplicateKey        = "duplicate-key"
+	LintRuleTrailingWhitespace  = "trailing-whitespace"
+	LintRuleUnexpectedExpansion = "unexpected-expansion"
+	LintRuleUndefinedVariable   = "undefined-variable"
+	LintRuleInvalidKey          = "invalid-key"
+	LintRuleCRLF                = "crlf"
+)
+
+// LintFinding is an issue detected by Lint, positioned by line and column (both 1-based)
+type LintFinding struct {
+	Filename string
+	Line     int
+	Column   int
+	Rule     string
+	Severity LintSeverity
+	Key      string
+	Message  string
+}
+
+func (f LintFinding) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s: %s (%s)", f.Filename, f.Line, f.Column, f.Severity, f.Message, f.Rule)
+}
+
+var (
+	parserErrorLineRegex = regexp.MustCompile(`^line (\d+): `)
+	// shellAssignmentRegex matches a shell variable assignment, possibly using a declaration builtin
+	shellAssignmentRegex = regexp.MustCompile(`^(?:(export|declare|typeset|readonly|local)((?:[ \t]+-\w+)*)[ \t]+)?([A-Za-z_][A-Za-z0-9_]*)(\+)?=`)
+)
+
+// LintFile checks an env file for common mistakes. See Lint
+func LintFile(filename string, lookupFn LookupFn) ([]LintFinding, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return Lint(file, filename, lookupFn)
+}
+
+// Lint checks an env file for common mistakes: duplicate keys, trailing whitespace in unquoted values,
+// `$` being expanded, shell assignments compose doesn't support, CRLF line endings and references
+// to variables neither declared by the env file nor available by lookupFn.
+// Syntax errors are reported as a LintError finding, and stop further checks.
+func Lint(r io.Reader, filename string, lookupFn LookupFn) ([]LintFinding, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if lookupFn == nil {
+		lookupFn = noLookupFn
+	}
+	l := &linter{
+		filename: filename,
+		src:      strings.TrimPrefix(string(data), string(utf8BOM)),
+		lookupFn: lookupFn,
+		declared: map[string]int{},
+	}
+	l.lint()
+	sort.SliceStable(l.findings, func(i, j int) bool {
+		if l.findings[i].Line != l.findings[j].Line {
+			return l.findings[i].Line < l.findings[j].Line
+		}
+		return l.findings[i].Column < l.findings[j].Column
+	})
+	return l.findings, nil
+}
+
+type linter struct {
+	filename string
+	src      string
+	lookupFn LookupFn
+	// declared maps keys to the line they were last declared on
+	declared map[string]int
+	findings []LintFinding
+}
+
+func (l *linter) lint() {
+	l.checkLineEndings()
+
+	p := newParser()
+	vars := map[string]string{}
+	cutset := l.src
+	for {
+		cutset = p.getStatementStart(cutset)
+		if cutset == "" {
+			return
+		}
+		keyOffset := l.offset(cutset)
+
+		key, left, inherited, err := p.locateKeyName(cutset)
+		if err == nil && strings.Contains(key, " ") {
+			err = fmt.Errorf("line %d: key cannot contain a space", p.line)
+		}
+		if err != nil && l.checkShellAssignment(cutset, keyOffset) {
+			// skip the statement and keep checking next ones
+			_, cutset, _ = strings.Cut(cutset, "\n")
+			p.line++
+			continue
+		}
+		if err != nil {
+			l.syntaxError(err, keyOffset)
+			return
+		}
+		l.checkKey(key, keyOffset)
+
+		if inherited {
+			if _, ok := l.lookupFn(key); !ok {
+				l.add(keyOffset, LintRuleUndefinedVariable, LintWarning, key,
+					fmt.Sprintf("%s is declared without a value but is not set in the environment", key))
+			}
+			l.declare(key, keyOffset)
+			cutset = left
+			continue
+		}
+
+		valueOffset := l.offset(left)
+		value, rest, err := p.extractVarValue(left, vars, func(k string) (string, bool) {
+			if v, ok := l.lookupFn(k); ok {
+				return v, true
+			}
+			// undefined variables are reported by checkReferences, prevent parser from logging warnings
+			return vars[k], true
+		})
+		if err != nil {
+			l.syntaxError(err, valueOffset)
+			return
+		}
+		raw := left[:len(left)-len(rest)]
+		quote, quoted := hasQuotePrefix(raw)
+		switch {
+		case !quoted:
+			l.checkTrailingWhitespace(key, raw, valueOffset)
+			raw, _, _ = strings.Cut(raw, "\n")
+			raw, _, _ = strings.Cut(raw, " #")
+			l.checkReferences(key, raw, valueOffset)
+		case quote == prefixDoubleQuote:
+			l.checkReferences(key, raw[1:len(raw)-1], valueOffset+1)
+		}
+
+		vars[key] = value
+		l.declare(key, keyOffset)
+		cutset = rest
+	}
+}
+
+func (l *linter) declare(key string, offset int) {
+	line, _ := l.position(offset)
+	l.declared[key] = line
+}
+
+func (l *linter) checkLineEndings() {
+	count := strings.Count(l.src, "\r\n")
+	if count == 0 {
+		return
+	}
+	l.add(strings.Index(l.src, "\r\n"), LintRuleCRLF, LintWarning, "",
+		fmt.Sprintf("file uses CRLF line endings on %d line(s), carriage returns may end up in unquoted values", count))
+}
+
+func (l *linter) checkKey(key string, offset int) {
+	if line, ok := l.declared[key]; ok {
+		l.add(offset, LintRuleDuplicateKey, LintWarning, key,
+			fmt.Sprintf("%s is already declared on line %d, previous value is overridden", key, line))
+	}
+}
+
+// checkShellAssignment reports statements a shell reads as a variable assignment, but compose fails to parse:
+// declaration builtins other than export, export options, and appending assignments
+func (l *linter) checkShellAssignment(statement string, offset int) bool {
+	match := shellAssignmentRegex.FindStringSubmatch(statement)
+	if match == nil {
+		return false
+	}
+	builtin, options, key, appending := match[1], match[2], match[3], match[4] != ""
+	switch {
+	case builtin != "" && builtin != "export":
+		l.add(offset, LintRuleInvalidKey, LintError, key,
+			fmt.Sprintf("%s is declared with shell builtin %s, only export is supported", key, builtin))
+	case options != "":
+		l.add(offset, LintRuleInvalidKey, LintError, key,
+			fmt.Sprintf("%s is exported with shell options %s, which are not supported", key, strings.TrimSpace(options)))
+	case appending:
+		l.add(offset, LintRuleInvalidKey, LintError, key,
+			fmt.Sprintf("%s is assigned with shell += operator, which is not supported", key))
+	default:
+		return false
+	}
+	return true
+}
+
+func (l *linter) checkTrailingWhitespace(key string, raw string, offset int) {
+	line, _, _ := strings.Cut(raw, "\n")
+	line = strings.TrimSuffix(line, "\r")
+	value, _, _ := strings.Cut(line, " #")
+	trimmed := strings.TrimRightFunc(value, unicode.IsSpace)
+	if trimmed == value || trimmed == "" {
+		return
+	}
+	l.add(offset+len(trimmed), LintRuleTrailingWhitespace, LintWarning, key,
+		fmt.Sprintf("trailing whitespace in unquoted value for %s is ignored, use quotes to keep it", key))
+}
+
+// checkReferences looks for variables in a raw value which will be expanded by the parser
+func (l *linter) checkReferences(key string, raw string, offset int) {
+	for _, match := range template.DefaultPattern.FindAllStringSubmatchIndex(raw, -1) {
+		start := match[0]
+		if escapedBackslashes(raw[:start])%2 == 1 {
+			// `\$` in double-quoted value is a literal `$`
+			continue
+		}
+		var name string
+		named := template.DefaultPattern.SubexpIndex("named")
+		braced := template.DefaultPattern.SubexpIndex("braced")
+		switch {
+		case match[2*named] >= 0:
+			name = raw[match[2*named]:match[2*named+1]]
+			l.add(offset+start, LintRuleUnexpectedExpansion, LintWarning, key,
+				fmt.Sprintf("$%s in value for %s will be expanded, use ${%s} to make it explicit or $$ for a literal $", name, key, name))
+		case match[2*braced] >= 0:
+			name = raw[match[2*braced]:match[2*braced+1]]
+			if strings.ContainsAny(name, ":-+?") {
+				// default value or required error are explicitly handled
+				continue
+			}
+		default:
+			continue
+		}
+		if _, ok := l.declared[name]; ok {
+			continue
+		}
+		if _, ok := l.lookupFn(name); ok {
+			continue
+		}
+		l.add(offset+start, LintRuleUndefinedVariable, LintWarning, key,
+			fmt.Sprintf("value for %s references undefined variable %s", key, name))
+	}
+}
+
+func escapedBackslashes(s string) int {
+	return len(s) - len(strings.TrimRight(s, `\`))
+}
+
+func (l *linter) syntaxError(err error, offset int) {
+	msg := err.Error()
+	line, column := l.position(offset)
+	if m := parserErrorLineRegex.FindStringSubmatch(msg); m != nil {
+		msg = strings.TrimPrefix(msg, m[0])
+	}
+	l.findings = append(l.findings, LintFinding{
+		Filename: l.filename,
+		Line:     line,
+		Column:   column,
+		Rule:     LintRuleSyntax,
+		Severity: LintError,
+		Message:  msg,
+	})
+}
+
+func (l *linter) add(offset int, rule string, severity LintSeverity, key string, message string) {
+	line, column := l.position(offset)
+	l.findings = append(l.findings, LintFinding{
+		Filename: l.filename,
+		Line:     line,
+		Column:   column,
+		Rule:     rule,
+		Severity: severity,
+		Key:      key,
+		Message:  message,
+	})
+}
+
+// offset returns the position of cutset within source
+func (l *linter) offset(cutset string) int {
+	return len(l.src) - len(cutset)
+}
+
+// position converts an offset within source into line and column
+func (l *linter) position(offset int) (int, int) {
+	before := l.src[:offset]
+	line := strings.Count(before, "\n") + 1
+	lineStart := strings.LastIndex(before, "\n") + 1
+	return line, utf8.RuneCountInString(before[lineStart:]) + 1
+}
@@ -0,0 +1,98 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dotenv
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestLint(t *testing.T) {
+	input := "FOO=bar\r\n" +
+		"FOO=zot  \n" +
+		"my.key=value\n" +
+		"PASSWORD=pa$word\n" +
+		`QUOTED="${HOME}/${MISSING}/${DEFAULT:-x}/\$LITERAL"` + "\n" +
+		"SINGLE='$NOT_EXPANDED'\n" +
+		"INHERITED\n"
+	lookup := func(k string) (string, bool) {
+		if k == "HOME" {
+			return "/home/user", true
+		}
+		return "", false
+	}
+	findings, err := Lint(strings.NewReader(input), ".env", lookup)
+	assert.NilError(t, err)
+
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.String())
+	}
+	assert.DeepEqual(t, []string{
+		".env:1:8: warning: file uses CRLF line endings on 1 line(s), carriage returns may end up in unquoted values (crlf)",
+		".env:2:1: warning: FOO is already declared on line 1, previous value is overridden (duplicate-key)",
+		".env:2:8: warning: trailing whitespace in unquoted value for FOO is ignored, use quotes to keep it (trailing-whitespace)",
+		".env:4:12: warning: $word in value for PASSWORD will be expanded, use ${word} to make it explicit or $$ for a literal $ (unexpected-expansion)",
+		".env:4:12: warning: value for PASSWORD references undefined variable word (undefined-variable)",
+		".env:5:17: warning: value for QUOTED references undefined variable MISSING (undefined-variable)",
+		".env:7:1: warning: INHERITED is declared without a value but is not set in the environment (undefined-variable)",
+	}, got)
+}
+
+func TestLintInvalidKey(t *testing.T) {
+	// keys a shell would reject are supported by compose
+	findings, err := Lint(strings.NewReader("my.key=1\nsome-key=2\n_private=3\nexport EXPORTED=4\n"), ".env", nil)
+	assert.NilError(t, err)
+	assert.Equal(t, len(findings), 0)
+
+	// assignments a shell accepts are rejected by compose
+	findings, err = Lint(strings.NewReader("readonly FOO=1\ndeclare -x BAR=2\nexport -n ZOT=3\nPATH+=:/opt/bin\nOK=${UNDEFINED}\n"), ".env", nil)
+	assert.NilError(t, err)
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.String())
+	}
+	assert.DeepEqual(t, []string{
+		".env:1:1: error: FOO is declared with shell builtin readonly, only export is supported (invalid-key)",
+		".env:2:1: error: BAR is declared with shell builtin declare, only export is supported (invalid-key)",
+		".env:3:1: error: ZOT is exported with shell options -n, which are not supported (invalid-key)",
+		".env:4:1: error: PATH is assigned with shell += operator, which is not supported (invalid-key)",
+		".env:5:4: warning: value for OK references undefined variable UNDEFINED (undefined-variable)",
+	}, got)
+
+	// a syntax error which isn't a shell assignment still stops checks
+	findings, err = Lint(strings.NewReader("FOO BAR=1\nreadonly ZOT=2\n"), ".env", nil)
+	assert.NilError(t, err)
+	assert.Equal(t, len(findings), 1)
+	assert.Equal(t, findings[0].Rule, LintRuleSyntax)
+}
+
+func TestLintSyntaxError(t *testing.T) {
+	findings, err := Lint(strings.NewReader("FOO=bar\nBAR=\"unterminated\n"), ".env", nil)
+	assert.NilError(t, err)
+	assert.Equal(t, len(findings), 1)
+	assert.DeepEqual(t, LintFinding{
+		Filename: ".env",
+		Line:     2,
+		Column:   5,
+		Rule:     LintRuleSyntax,
+		Severity: LintError,
+		Message:  `unterminated quoted value "unterminated`,
+	}, findings[0])
+}
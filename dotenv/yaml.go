@@ -0,0 +1,87 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package dotenv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// parseYAML reads a YAML mapping as env file. Nested mappings and sequences are flattened
+// by joining keys (or sequence indexes) with `_`, and null is set as an empty string
+func parseYAML(r io.Reader, filename string, vars map[string]string, _ func(key string) (string, bool)) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	data = bytes.TrimPrefix(data, utf8BOM)
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	if doc.Kind == 0 {
+		// empty document
+		return nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("failed to read %s: line %d: top-level value must be a mapping", filename, root.Line)
+	}
+	if err := flattenYAML(root, "", newFlattenedVars(vars)); err != nil {
+		return fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+	return nil
+}
+
+func flattenYAML(node *yaml.Node, key string, out *flattenedVars) error {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			k := node.Content[i]
+			if k.ShortTag() == "!!merge" {
+				return fmt.Errorf("line %d: merge keys are not supported", k.Line)
+			}
+			if k.Kind != yaml.ScalarNode {
+				return fmt.Errorf("line %d: keys must be scalar values", k.Line)
+			}
+			if err := flattenYAML(node.Content[i+1], flattenKey(key, k.Value), out); err != nil {
+				return err
+			}
+		}
+		return nil
+	case yaml.SequenceNode:
+		for i, item := range node.Content {
+			if err := flattenYAML(item, flattenKey(key, strconv.Itoa(i)), out); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		if node.ShortTag() == "!!null" {
+			return out.set(key, "", node.Line)
+		}
+		return out.set(key, node.Value, node.Line)
+	}
+}
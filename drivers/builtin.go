@@ -0,0 +1,143 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package drivers
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+func init() {
+	Register(Logging, "json-file", Schema{
+		Strict: true,
+		Options: logOptions(map[string]Option{
+			"max-size": {Type: Bytes},
+			"max-file": {Type: Int},
+			"compress": {Type: Bool},
+		}),
+	})
+	Register(Logging, "local", Schema{
+		Strict: true,
+		Options: withCommonLogOptions(map[string]Option{
+			"max-size": {Type: Bytes},
+			"max-file": {Type: Int},
+			"compress": {Type: Bool},
+		}),
+	})
+	Register(Logging, "syslog", Schema{
+		Strict: true,
+		Options: logOptions(map[string]Option{
+			"syslog-address": {Type: String, Check: checkSyslogAddress},
+			"syslog-facility": {Type: Enum, Values: []string{
+				"kern", "user", "mail", "daemon", "auth", "syslog", "lpr", "news", "uucp", "cron", "authpriv", "ftp",
+				"local0", "local1", "local2", "local3", "local4", "local5", "local6", "local7",
+			}},
+			"syslog-tls-ca-cert":     {Type: String},
+			"syslog-tls-cert":        {Type: String},
+			"syslog-tls-key":         {Type: String},
+			"syslog-tls-skip-verify": {Type: Bool},
+			"syslog-format":          {Type: Enum, Values: []string{"rfc5424", "rfc5424micro", "rfc3164"}},
+		}),
+	})
+	Register(Logging, "journald", Schema{
+		Strict:  true,
+		Options: logOptions(map[string]Option{}),
+	})
+
+	Register(Network, "bridge", Schema{
+		Prefixes: []string{"com.docker.network.bridge."},
+		Options: map[string]Option{
+			"com.docker.network.bridge.name":                    {Type: String},
+			"com.docker.network.bridge.enable_ip_masquerade":    {Type: Bool},
+			"com.docker.network.bridge.enable_icc":              {Type: Bool},
+			"com.docker.network.bridge.host_binding_ipv4":       {Type: IP},
+			"com.docker.network.bridge.inhibit_ipv4":            {Type: Bool},
+			"com.docker.network.bridge.gateway_mode_ipv4":       {Type: Enum, Values: gatewayModes},
+			"com.docker.network.bridge.gateway_mode_ipv6":       {Type: Enum, Values: gatewayModes},
+			"com.docker.network.bridge.trusted_host_interfaces": {Type: String},
+			"com.docker.network.driver.mtu":                     {Type: Int},
+			"com.docker.network.container_iface_prefix":         {Type: String},
+		},
+	})
+	Register(Network, "macvlan", Schema{
+		Options: map[string]Option{
+			"parent":                        {Type: String},
+			"macvlan_mode":                  {Type: Enum, Values: []string{"bridge", "vepa", "passthru", "private"}},
+			"com.docker.network.driver.mtu": {Type: Int},
+		},
+	})
+
+	Register(Volume, "local", Schema{
+		Strict: true,
+		Options: map[string]Option{
+			"type":   {Type: String},
+			"o":      {Type: String, Check: checkMountOptions},
+			"device": {Type: String},
+			"size":   {Type: Bytes},
+		},
+	})
+}
+
+var gatewayModes = []string{"nat", "nat-unprotected", "routed", "isolated"}
+
+// withCommonLogOptions adds options supported by the engine for all logging drivers
+func withCommonLogOptions(options map[string]Option) map[string]Option {
+	options["mode"] = Option{Type: Enum, Values: []string{"blocking", "non-blocking"}}
+	options["max-buffer-size"] = Option{Type: Bytes}
+	return options
+}
+
+// logOptions adds options supported by logging drivers to add container metadata to log entries
+func logOptions(options map[string]Option) map[string]Option {
+	for _, name := range []string{"labels", "labels-regex", "env", "env-regex", "tag"} {
+		options[name] = Option{Type: String}
+	}
+	return withCommonLogOptions(options)
+}
+
+func checkSyslogAddress(value string, _ map[string]string) error {
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme == "" {
+		return fmt.Errorf("expected an address, such as udp://host:port")
+	}
+	switch u.Scheme {
+	case "udp", "tcp", "tcp+tls", "unix", "unixgram":
+		return nil
+	}
+	return fmt.Errorf("unsupported protocol %s", u.Scheme)
+}
+
+// checkMountOptions checks mount options set by `o` for a local volume
+func checkMountOptions(value string, options map[string]string) error {
+	for _, opt := range strings.Split(value, ",") {
+		name, _, _ := strings.Cut(opt, "=")
+		switch strings.TrimSpace(name) {
+		case "":
+			return fmt.Errorf("empty mount option")
+		case "bind", "rbind":
+			if options["device"] == "" {
+				return fmt.Errorf("%s mount requires a device", name)
+			}
+		case "addr":
+			if !strings.HasPrefix(options["type"], "nfs") && options["type"] != "cifs" {
+				return fmt.Errorf("addr requires type nfs or cifs")
+			}
+		}
+	}
+	return nil
+}
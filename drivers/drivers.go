@@ -0,0 +1,224 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package drivers
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/errdefs"
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/compose-spec/compose-go/v2/utils"
+	"github.com/docker/go-units"
+)
+
+// Kind is the kind of resource a driver manages
+type Kind string
+
+const (
+	Logging Kind = "logging"
+	Network Kind = "network"
+	Volume  Kind = "volume"
+)
+
+// ValueType is the expected type of a driver option value
+type ValueType string
+
+const (
+	String   ValueType = "string"
+	Int      ValueType = "int"
+	Bool     ValueType = "bool"
+	Bytes    ValueType = "bytes"
+	Duration ValueType = "duration"
+	IP       ValueType = "ip"
+	Enum     ValueType = "enum"
+)
+
+// Option describes a driver option
+type Option struct {
+	Type ValueType
+	// Values lists accepted values for Enum options
+	Values []string
+	// Check is an optional validation, which can inspect other options set for the driver
+	Check func(value string, options map[string]string) error
+}
+
+// Schema describes the options supported by a driver
+type Schema struct {
+	Options map[string]Option
+	// Strict rejects options which are not declared by the schema
+	Strict bool
+	// Prefixes rejects undeclared options with one of these prefixes, for drivers accepting
+	// arbitrary options but using a namespace for well known ones
+	Prefixes []string
+}
+
+type key struct {
+	kind   Kind
+	driver string
+}
+
+var schemas = map[key]Schema{}
+
+// Register sets the Schema for driver options, replacing any schema previously registered
+func Register(kind Kind, driver string, schema Schema) {
+	schemas[key{kind: kind, driver: driver}] = schema
+}
+
+// Lookup returns the Schema registered for a driver
+func Lookup(kind Kind, driver string) (Schema, bool) {
+	s, ok := schemas[key{kind: kind, driver: driver}]
+	return s, ok
+}
+
+// Validate checks options against the schema. Options set to an empty value, typically after
+// interpolation of an unset variable, are considered unset
+func (s Schema) Validate(options map[string]string) error {
+	for _, name := range utils.MapKeys(options) {
+		value := options[name]
+		if value == "" {
+			continue
+		}
+		opt, ok := s.Options[name]
+		if !ok {
+			if s.Strict || slices.ContainsFunc(s.Prefixes, func(prefix string) bool {
+				return strings.HasPrefix(name, prefix)
+			}) {
+				return s.unknown(name)
+			}
+			continue
+		}
+		if err := opt.validate(value); err != nil {
+			return fmt.Errorf("invalid value %q for option %q: %w", value, name, err)
+		}
+		if opt.Check != nil {
+			if err := opt.Check(value, options); err != nil {
+				return fmt.Errorf("invalid value %q for option %q: %w", value, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (s Schema) unknown(name string) error {
+	normalized := normalize(name)
+	for _, candidate := range utils.MapKeys(s.Options) {
+		if normalize(candidate) == normalized {
+			return fmt.Errorf("unknown option %q, did you mean %q?", name, candidate)
+		}
+	}
+	return fmt.Errorf("unknown option %q", name)
+}
+
+func normalize(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", "-"))
+}
+
+func (o Option) validate(value string) error {
+	switch o.Type {
+	case Int:
+		if _, err := strconv.Atoi(value); err != nil {
+			return fmt.Errorf("expected an integer")
+		}
+	case Bool:
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("expected a boolean")
+		}
+	case Bytes:
+		if _, err := units.RAMInBytes(value); err != nil {
+			return fmt.Errorf("expected a size, such as 10m")
+		}
+	case Duration:
+		if _, err := time.ParseDuration(value); err != nil {
+			return fmt.Errorf("expected a duration, such as 10s")
+		}
+	case IP:
+		if net.ParseIP(value) == nil {
+			return fmt.Errorf("expected an IP address")
+		}
+	case Enum:
+		if !slices.Contains(o.Values, value) {
+			return fmt.Errorf("expected one of %s", strings.Join(o.Values, ", "))
+		}
+	}
+	return nil
+}
+
+// ValidateProject checks logging, network and volume driver options set by a project against
+// the registered schemas. Services without a logging driver are checked as using json-file,
+// networks without a driver as bridge networks, volumes without a driver as local volumes.
+// Options for drivers without a registered schema are not checked
+func ValidateProject(project *types.Project) error {
+	for _, name := range project.ServiceNames() {
+		s := project.Services[name]
+		if s.Logging == nil {
+			continue
+		}
+		driver := s.Logging.Driver
+		if driver == "" {
+			driver = "json-file"
+		}
+		if err := validate(Logging, driver, s.Logging.Options); err != nil {
+			return fmt.Errorf("services.%s.logging.options: %w", name, err)
+		}
+	}
+	for _, name := range project.NetworkNames() {
+		n := project.Networks[name]
+		if n.External {
+			continue
+		}
+		driver := n.Driver
+		if driver == "" {
+			driver = "bridge"
+		}
+		if err := validate(Network, driver, n.DriverOpts); err != nil {
+			return fmt.Errorf("networks.%s.driver_opts: %w", name, err)
+		}
+	}
+	for _, name := range project.VolumeNames() {
+		v := project.Volumes[name]
+		if v.External {
+			continue
+		}
+		driver := v.Driver
+		if driver == "" {
+			driver = "local"
+		}
+		if err := validate(Volume, driver, v.DriverOpts); err != nil {
+			return fmt.Errorf("volumes.%s.driver_opts: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func validate(kind Kind, driver string, options map[string]string) error {
+	if len(options) == 0 {
+		return nil
+	}
+	schema, ok := Lookup(kind, driver)
+	if !ok {
+		return nil
+	}
+	if err := schema.Validate(options); err != nil {
+		return fmt.Errorf("%s %s driver: %w: %w", driver, kind, err, errdefs.ErrInvalid)
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package drivers
+
+import (
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/errdefs"
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func TestValidateProject(t *testing.T) {
+	tests := []struct {
+		name     string
+		project  types.Project
+		expected string
+	}{
+		{
+			name: "valid",
+			project: types.Project{
+				Services: types.Services{
+					"web": {Name: "web", Logging: &types.LoggingConfig{Driver: "json-file", Options: types.Options{
+						"max-size": "10m", "max-file": "3", "mode": "non-blocking", "tag": "{{.Name}}",
+					}}},
+					"api": {Name: "api", Logging: &types.LoggingConfig{Driver: "syslog", Options: types.Options{
+						"syslog-address": "tcp://192.168.0.42:123", "syslog-facility": "daemon",
+					}}},
+					"db": {Name: "db", Logging: &types.LoggingConfig{Driver: "custom", Options: types.Options{"foo": "bar"}}},
+				},
+				Networks: types.Networks{
+					"front": {Driver: "bridge", DriverOpts: types.Options{
+						"com.docker.network.bridge.enable_icc": "false", "beep": "boop",
+					}},
+					"lan":  {Driver: "macvlan", DriverOpts: types.Options{"parent": "eth0", "macvlan_mode": "bridge"}},
+					"back": {DriverOpts: types.Options{"com.docker.network.bridge.name": "br-back"}},
+				},
+				Volumes: types.Volumes{
+					"data":  {DriverOpts: types.Options{"type": "none", "o": "bind", "device": "./data"}},
+					"nfs":   {Driver: "local", DriverOpts: types.Options{"type": "nfs", "o": "addr=10.0.0.1,rw", "device": ":/export"}},
+					"other": {Driver: "flocker", DriverOpts: types.Options{"foo": "bar"}},
+					"ext":   {External: true, DriverOpts: types.Options{"foo": "bar"}},
+				},
+			},
+		},
+		{
+			name: "empty values",
+			project: types.Project{
+				Services: types.Services{
+					"web": {Name: "web", Logging: &types.LoggingConfig{Driver: "json-file", Options: types.Options{
+						"max-size": "", "max-file": "",
+					}}},
+				},
+				Volumes: types.Volumes{
+					"data": {DriverOpts: types.Options{"size": ""}},
+				},
+			},
+		},
+		{
+			name: "default logging driver",
+			project: types.Project{Services: types.Services{
+				"web": {Name: "web", Logging: &types.LoggingConfig{Options: types.Options{"max-file": "three"}}},
+			}},
+			expected: `services.web.logging.options: json-file logging driver: invalid value "three" for option "max-file": expected an integer: invalid compose project`,
+		},
+		{
+			name: "typo",
+			project: types.Project{Services: types.Services{
+				"web": {Name: "web", Logging: &types.LoggingConfig{Driver: "json-file", Options: types.Options{"max_size": "10m"}}},
+			}},
+			expected: `services.web.logging.options: json-file logging driver: unknown option "max_size", did you mean "max-size"?: invalid compose project`,
+		},
+		{
+			name: "invalid size",
+			project: types.Project{Services: types.Services{
+				"web": {Name: "web", Logging: &types.LoggingConfig{Driver: "local", Options: types.Options{"max-size": "ten"}}},
+			}},
+			expected: `services.web.logging.options: local logging driver: invalid value "ten" for option "max-size": expected a size, such as 10m: invalid compose project`,
+		},
+		{
+			name: "invalid enum",
+			project: types.Project{Services: types.Services{
+				"web": {Name: "web", Logging: &types.LoggingConfig{Driver: "syslog", Options: types.Options{"syslog-format": "json"}}},
+			}},
+			expected: `services.web.logging.options: syslog logging driver: invalid value "json" for option "syslog-format": expected one of rfc5424, rfc5424micro, rfc3164: invalid compose project`,
+		},
+		{
+			name: "unknown journald option",
+			project: types.Project{Services: types.Services{
+				"web": {Name: "web", Logging: &types.LoggingConfig{Driver: "journald", Options: types.Options{"max-size": "1m"}}},
+			}},
+			expected: `services.web.logging.options: journald logging driver: unknown option "max-size": invalid compose project`,
+		},
+		{
+			name: "unknown bridge option",
+			project: types.Project{Networks: types.Networks{
+				"front": {Driver: "bridge", DriverOpts: types.Options{"com.docker.network.bridge.enable_ipmasquerade": "true"}},
+			}},
+			expected: `networks.front.driver_opts: bridge network driver: unknown option "com.docker.network.bridge.enable_ipmasquerade": invalid compose project`,
+		},
+		{
+			name: "default network driver",
+			project: types.Project{Networks: types.Networks{
+				"default": {DriverOpts: types.Options{"com.docker.network.bridge.enable_icc": "maybe"}},
+			}},
+			expected: `networks.default.driver_opts: bridge network driver: invalid value "maybe" for option "com.docker.network.bridge.enable_icc": expected a boolean: invalid compose project`,
+		},
+		{
+			name: "invalid macvlan mode",
+			project: types.Project{Networks: types.Networks{
+				"lan": {Driver: "macvlan", DriverOpts: types.Options{"macvlan_mode": "bridged"}},
+			}},
+			expected: `networks.lan.driver_opts: macvlan network driver: invalid value "bridged" for option "macvlan_mode": expected one of bridge, vepa, passthru, private: invalid compose project`,
+		},
+		{
+			name: "bind without device",
+			project: types.Project{Volumes: types.Volumes{
+				"data": {DriverOpts: types.Options{"type": "none", "o": "bind"}},
+			}},
+			expected: `volumes.data.driver_opts: local volume driver: invalid value "bind" for option "o": bind mount requires a device: invalid compose project`,
+		},
+		{
+			name: "unknown local volume option",
+			project: types.Project{Volumes: types.Volumes{
+				"data": {DriverOpts: types.Options{"Type": "tmpfs"}},
+			}},
+			expected: `volumes.data.driver_opts: local volume driver: unknown option "Type", did you mean "type"?: invalid compose project`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateProject(&tt.project)
+			if tt.expected == "" {
+				assert.NilError(t, err)
+				return
+			}
+			assert.Error(t, err, tt.expected)
+			assert.Assert(t, errdefs.IsInvalidError(err))
+		})
+	}
+}
+
+func TestRegister(t *testing.T) {
+	Register(Volume, "example", Schema{
+		Strict: true,
+		Options: map[string]Option{
+			"replicas": {Type: Int},
+		},
+	})
+	t.Cleanup(func() {
+		delete(schemas, key{kind: Volume, driver: "example"})
+	})
+	_, ok := Lookup(Volume, "example")
+	assert.Assert(t, ok)
+
+	err := ValidateProject(&types.Project{Volumes: types.Volumes{
+		"data": {Driver: "example", DriverOpts: types.Options{"replicas": "two"}},
+	}})
+	assert.Error(t, err, `volumes.data.driver_opts: example volume driver: invalid value "two" for option "replicas": expected an integer: invalid compose project`)
+}
@@ -25,9 +25,14 @@ import (
 	"github.com/compose-spec/compose-go/v2/utils"
 )
 
-// CheckCycle analyze project's depends_on relation and report an error on cycle detection
-func CheckCycle(project *types.Project) error {
-	g, err := newGraph(project)
+// CheckCycle analyze project's depends_on relation and report an error on cycle detection.
+// WithImplicitDependencies option extends the analysis to implicit dependencies
+func CheckCycle(project *types.Project, options ...func(*Options)) error {
+	o := &Options{}
+	for _, option := range options {
+		option(o)
+	}
+	g, err := newGraph(project, o.implicitDependencies)
 	if err != nil {
 		return err
 	}
@@ -39,7 +44,7 @@ func (g *graph[T]) checkCycle() error {
 	// this is required by tests and enforce command reproducibility by user, which otherwise could be confusing
 	names := utils.MapKeys(g.vertices)
 	for _, name := range names {
-		err := searchCycle([]string{name}, g.vertices[name])
+		err := searchCycle([]*vertex[T]{g.vertices[name]})
 		if err != nil {
 			return err
 		}
@@ -47,17 +52,38 @@ func (g *graph[T]) checkCycle() error {
 	return nil
 }
 
-func searchCycle[T any](path []string, v *vertex[T]) error {
+func searchCycle[T any](path []*vertex[T]) error {
+	v := path[len(path)-1]
 	names := utils.MapKeys(v.children)
 	for _, name := range names {
-		if i := slices.Index(path, name); i >= 0 {
-			return fmt.Errorf("dependency cycle detected: %s -> %s", strings.Join(path[i:], " -> "), name)
-		}
 		ch := v.children[name]
-		err := searchCycle(append(path, name), ch)
+		if i := slices.Index(path, ch); i >= 0 {
+			return fmt.Errorf("dependency cycle detected: %s", formatCycle(append(path[i:], ch)))
+		}
+		err := searchCycle(append(path, ch))
 		if err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+// formatCycle renders a dependency cycle, naming the attributes declaring an edge unless it only comes from depends_on
+func formatCycle[T any](cycle []*vertex[T]) string {
+	var sb strings.Builder
+	sb.WriteString(cycle[0].key)
+	for i := 1; i < len(cycle); i++ {
+		kinds := cycle[i-1].kinds[cycle[i].key]
+		if len(kinds) == 0 || slices.Equal(kinds, []types.DependencyKind{types.DependencyDependsOn}) {
+			sb.WriteString(" -> ")
+		} else {
+			labels := make([]string, len(kinds))
+			for j, kind := range kinds {
+				labels[j] = string(kind)
+			}
+			fmt.Fprintf(&sb, " -[%s]-> ", strings.Join(labels, ","))
+		}
+		sb.WriteString(cycle[i].key)
+	}
+	return sb.String()
+}
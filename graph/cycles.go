@@ -0,0 +1,372 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package graph
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/tree"
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/compose-spec/compose-go/v2/utils"
+	"go.yaml.in/yaml/v4"
+)
+
+// Cycle is a set of services which depend on each other, i.e. a strongly connected component of the
+// service graph
+type Cycle struct {
+	// Services are the members of the cycle, sorted by name
+	Services []string
+	// Path is an ordered cycle through the component, starting and ending with the first service
+	Path []CycleEdge
+	// Edges are all the dependencies between members of the cycle, sorted by source then target service
+	Edges []CycleEdge
+	// Suggestions are the dependencies which could be relaxed to break the cycle
+	Suggestions []Suggestion
+}
+
+// CycleEdge is a dependency between two services in a Cycle
+type CycleEdge struct {
+	From string
+	To   string
+	// Required is false for a depends_on dependency declared with required: false
+	Required bool
+	// Condition is the depends_on condition, empty for implicit dependencies
+	Condition string
+	// Sources are the attributes declaring the dependency
+	Sources []EdgeSource
+}
+
+// EdgeSource is an attribute declaring a dependency
+type EdgeSource struct {
+	Kind types.DependencyKind
+	Path tree.Path
+	// Filename, Line and Column are set by Locate when the attribute is found in a compose file
+	Filename string
+	Line     int
+	Column   int
+}
+
+func (s EdgeSource) String() string {
+	if s.Filename == "" {
+		return s.Path.String()
+	}
+	return fmt.Sprintf("%s (%s:%d:%d)", s.Path, s.Filename, s.Line, s.Column)
+}
+
+// Suggestion is a dependency which could be relaxed to break a Cycle
+type Suggestion struct {
+	Edge   CycleEdge
+	Reason string
+	// Breaks is set when relaxing this dependency alone breaks all cycles between the services
+	Breaks bool
+}
+
+func (c Cycle) String() string {
+	var sb strings.Builder
+	sb.WriteString(c.Path[0].From)
+	for _, e := range c.Path {
+		kinds := make([]string, 0, len(e.Sources))
+		for _, source := range e.Sources {
+			if !slices.Contains(kinds, string(source.Kind)) {
+				kinds = append(kinds, string(source.Kind))
+			}
+		}
+		if len(kinds) == 1 && kinds[0] == string(types.DependencyDependsOn) {
+			sb.WriteString(" -> ")
+		} else {
+			fmt.Fprintf(&sb, " -[%s]-> ", strings.Join(kinds, ","))
+		}
+		sb.WriteString(e.To)
+	}
+	return sb.String()
+}
+
+// Cycles is the list of dependency cycles in a project
+type Cycles []Cycle
+
+// Locate sets the source position of the attributes declaring cycle edges found in a yaml document.
+// When a project is loaded from multiple files, Locate is expected to be called for each file in
+// loading order, so that edges point to the file which set the effective value
+func (cs Cycles) Locate(filename string, root *yaml.Node) {
+	for _, c := range cs {
+		for _, edges := range [][]CycleEdge{c.Path, c.Edges} {
+			for _, e := range edges {
+				locateSources(e.Sources, filename, root)
+			}
+		}
+		for _, s := range c.Suggestions {
+			locateSources(s.Edge.Sources, filename, root)
+		}
+	}
+}
+
+func locateSources(sources []EdgeSource, filename string, root *yaml.Node) {
+	for i, s := range sources {
+		node := s.Path.Node(root)
+		if node == nil {
+			continue
+		}
+		sources[i].Filename = filename
+		sources[i].Line = node.Line
+		sources[i].Column = node.Column
+	}
+}
+
+// LocateFiles parses compose files in loading order and sets the source position of cycle edges
+func (cs Cycles) LocateFiles(filenames ...string) error {
+	for _, filename := range filenames {
+		root, err := tree.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		cs.Locate(filename, root)
+	}
+	return nil
+}
+
+// FindCycles reports all dependency cycles in project, as the strongly connected components of the service
+// graph. WithImplicitDependencies option extends the analysis to implicit dependencies
+func FindCycles(project *types.Project, options ...func(*Options)) (Cycles, error) {
+	o := &Options{}
+	for _, option := range options {
+		option(o)
+	}
+	g, err := buildGraph(project, o.implicitDependencies)
+	if err != nil {
+		return nil, err
+	}
+
+	var cycles Cycles
+	for _, component := range g.stronglyConnectedComponents() {
+		if len(component) == 1 {
+			v := g.vertices[component[0]]
+			if _, self := v.children[v.key]; !self {
+				continue
+			}
+		}
+		members := utils.NewSet(component...)
+		c := Cycle{Services: component}
+		for _, name := range component {
+			v := g.vertices[name]
+			for _, dep := range utils.MapKeys(v.children) {
+				if members.Has(dep) {
+					c.Edges = append(c.Edges, cycleEdge(project, v, dep))
+				}
+			}
+		}
+		for _, step := range g.shortestCycle(component[0], members) {
+			c.Path = append(c.Path, cycleEdge(project, g.vertices[step[0]], step[1]))
+		}
+		c.Suggestions = g.suggest(c, members)
+		cycles = append(cycles, c)
+	}
+	return cycles, nil
+}
+
+// stronglyConnectedComponents implements Tarjan's algorithm, visiting vertices in name order so that
+// components are returned in a predictable order, each sorted by name
+func (g *graph[T]) stronglyConnectedComponents() [][]string {
+	var (
+		index      int
+		stack      []string
+		onStack    = map[string]bool{}
+		indices    = map[string]int{}
+		lowlinks   = map[string]int{}
+		components [][]string
+	)
+	var connect func(name string)
+	connect = func(name string) {
+		indices[name] = index
+		lowlinks[name] = index
+		index++
+		stack = append(stack, name)
+		onStack[name] = true
+
+		for _, child := range utils.MapKeys(g.vertices[name].children) {
+			if _, visited := indices[child]; !visited {
+				connect(child)
+				lowlinks[name] = min(lowlinks[name], lowlinks[child])
+			} else if onStack[child] {
+				lowlinks[name] = min(lowlinks[name], indices[child])
+			}
+		}
+
+		if lowlinks[name] == indices[name] {
+			var component []string
+			for {
+				n := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[n] = false
+				component = append(component, n)
+				if n == name {
+					break
+				}
+			}
+			slices.Sort(component)
+			components = append(components, component)
+		}
+	}
+	for _, name := range utils.MapKeys(g.vertices) {
+		if _, visited := indices[name]; !visited {
+			connect(name)
+		}
+	}
+	slices.SortFunc(components, func(a, b []string) int {
+		return strings.Compare(a[0], b[0])
+	})
+	return components
+}
+
+// shortestCycle returns the edges of the shortest cycle from start back to itself within members
+func (g *graph[T]) shortestCycle(start string, members utils.Set[string]) [][2]string {
+	previous := map[string]string{}
+	queue := []string{start}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, child := range utils.MapKeys(g.vertices[name].children) {
+			if !members.Has(child) {
+				continue
+			}
+			if child == start {
+				steps := [][2]string{{name, start}}
+				for n := name; n != start; n = previous[n] {
+					steps = append(steps, [2]string{previous[n], n})
+				}
+				slices.Reverse(steps)
+				return steps
+			}
+			if _, seen := previous[child]; !seen {
+				previous[child] = name
+				queue = append(queue, child)
+			}
+		}
+	}
+	return nil
+}
+
+// suggest lists the depends_on dependencies within a cycle which could be relaxed, optional ones first
+func (g *graph[T]) suggest(c Cycle, members utils.Set[string]) []Suggestion {
+	var suggestions []Suggestion
+	for _, e := range c.Edges {
+		if len(e.Sources) != 1 || e.Sources[0].Kind != types.DependencyDependsOn {
+			// implicit dependencies can't be relaxed
+			continue
+		}
+		var reason string
+		switch {
+		case !e.Required:
+			reason = fmt.Sprintf("dependency on %q is not required and can be removed", e.To)
+		case e.Condition == types.ServiceConditionStarted:
+			reason = fmt.Sprintf("dependency only waits for %q to be started, consider setting required: false or removing it", e.To)
+		default:
+			reason = fmt.Sprintf("consider relaxing %s condition on %q", e.Condition, e.To)
+		}
+		suggestions = append(suggestions, Suggestion{
+			Edge:   e,
+			Reason: reason,
+			Breaks: !g.hasCycleWithout(members, e.From, e.To),
+		})
+	}
+	rank := func(s Suggestion) int {
+		switch {
+		case !s.Edge.Required:
+			return 0
+		case s.Edge.Condition == types.ServiceConditionStarted:
+			return 1
+		}
+		return 2
+	}
+	slices.SortStableFunc(suggestions, func(a, b Suggestion) int {
+		if a.Breaks != b.Breaks {
+			if a.Breaks {
+				return -1
+			}
+			return 1
+		}
+		return rank(a) - rank(b)
+	})
+	return suggestions
+}
+
+// hasCycleWithout checks for a cycle among members once the edge from -> to is removed
+func (g *graph[T]) hasCycleWithout(members utils.Set[string], from, to string) bool {
+	sub := &graph[T]{vertices: map[string]*vertex[T]{}}
+	for name := range members {
+		sub.addVertex(name, *g.vertices[name].service)
+	}
+	for name := range members {
+		for child := range g.vertices[name].children {
+			if members.Has(child) && (name != from || child != to) {
+				sub.addEdge(name, child)
+			}
+		}
+	}
+	return sub.checkCycle() != nil
+}
+
+func cycleEdge(project *types.Project, v *vertex[types.ServiceConfig], dep string) CycleEdge {
+	e := CycleEdge{
+		From:     v.key,
+		To:       dep,
+		Required: true,
+	}
+	service := tree.NewPath("services").Next(v.key)
+	for _, kind := range v.kinds[dep] {
+		switch kind {
+		case types.DependencyDependsOn:
+			d := v.service.DependsOn[dep]
+			// implicit dependencies can't be optional
+			e.Required = d.Required || len(v.kinds[dep]) > 1
+			e.Condition = d.Condition
+			if e.Condition == "" {
+				e.Condition = types.ServiceConditionStarted
+			}
+			e.Sources = append(e.Sources, EdgeSource{Kind: kind, Path: service.Next("depends_on").Next(dep)})
+		case types.DependencyNetworkMode, types.DependencyIpc, types.DependencyPid:
+			e.Sources = append(e.Sources, EdgeSource{Kind: kind, Path: service.Next(string(kind))})
+		case types.DependencyVolumesFrom:
+			for i, ref := range v.service.VolumesFrom {
+				if name, _, _ := strings.Cut(ref, ":"); name == dep {
+					e.Sources = append(e.Sources, EdgeSource{Kind: kind, Path: service.Next("volumes_from").Next(strconv.Itoa(i))})
+				}
+			}
+		case types.DependencyLinks:
+			for i, ref := range v.service.Links {
+				if name, _, _ := strings.Cut(ref, ":"); name == dep {
+					e.Sources = append(e.Sources, EdgeSource{Kind: kind, Path: service.Next("links").Next(strconv.Itoa(i))})
+				}
+			}
+		case types.DependencyAdditionalContexts:
+			for _, name := range utils.MapKeys(v.service.Build.AdditionalContexts) {
+				if v.service.Build.AdditionalContexts[name] == types.ServicePrefix+dep {
+					e.Sources = append(e.Sources, EdgeSource{Kind: kind, Path: service.Next("build").Next("additional_contexts").Next(name)})
+				}
+			}
+		case types.DependencyModels:
+			for _, name := range utils.MapKeys(v.service.Models) {
+				if slices.Contains(project.ModelProviders(name), dep) {
+					e.Sources = append(e.Sources, EdgeSource{Kind: kind, Path: service.Next("models").Next(name)})
+				}
+			}
+		}
+	}
+	return e
+}
@@ -0,0 +1,183 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package graph
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/tree"
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func cyclicProject() *types.Project {
+	return &types.Project{
+		Services: types.Services{
+			"a": {
+				Name:      "a",
+				DependsOn: types.DependsOnConfig{"b": {Condition: types.ServiceConditionHealthy, Required: true}},
+			},
+			"b": {
+				Name:      "b",
+				DependsOn: types.DependsOnConfig{"c": {Condition: types.ServiceConditionStarted}},
+			},
+			"c": {
+				Name:        "c",
+				NetworkMode: "service:a",
+			},
+			"d": {
+				Name:      "d",
+				DependsOn: types.DependsOnConfig{"d": {Condition: types.ServiceConditionStarted, Required: true}},
+			},
+			"e": {
+				Name:      "e",
+				DependsOn: types.DependsOnConfig{"f": {Condition: types.ServiceConditionStarted, Required: true}},
+			},
+			"f": {Name: "f"},
+		},
+	}
+}
+
+func TestFindCycles(t *testing.T) {
+	cycles, err := FindCycles(cyclicProject())
+	assert.NilError(t, err)
+	assert.Equal(t, len(cycles), 1)
+	assert.DeepEqual(t, cycles[0].Services, []string{"d"})
+	assert.Equal(t, cycles[0].String(), "d -> d")
+
+	cycles, err = FindCycles(cyclicProject(), WithImplicitDependencies)
+	assert.NilError(t, err)
+	assert.Equal(t, len(cycles), 2)
+
+	c := cycles[0]
+	assert.DeepEqual(t, c.Services, []string{"a", "b", "c"})
+	assert.Equal(t, c.String(), "a -> b -> c -[network_mode]-> a")
+	assert.Equal(t, len(c.Edges), 3)
+	assert.DeepEqual(t, c.Path[2], CycleEdge{
+		From:     "c",
+		To:       "a",
+		Required: true,
+		Sources: []EdgeSource{
+			{Kind: types.DependencyNetworkMode, Path: tree.NewPath("services", "c", "network_mode")},
+		},
+	})
+
+	assert.Equal(t, len(c.Suggestions), 2)
+	assert.Equal(t, c.Suggestions[0].Edge.From, "b")
+	assert.Equal(t, c.Suggestions[0].Reason, `dependency on "c" is not required and can be removed`)
+	assert.Assert(t, c.Suggestions[0].Breaks)
+	assert.Equal(t, c.Suggestions[1].Edge.From, "a")
+	assert.Equal(t, c.Suggestions[1].Reason, `consider relaxing service_healthy condition on "b"`)
+}
+
+func TestFindCyclesModels(t *testing.T) {
+	project := &types.Project{
+		Models: types.Models{"llm": {Model: "ai/smollm2"}},
+		Services: types.Services{
+			"app": {Name: "app", Models: map[string]*types.ServiceModelConfig{"llm": {}}},
+			"runner": {
+				Name:      "runner",
+				DependsOn: types.DependsOnConfig{"app": {Condition: types.ServiceConditionStarted, Required: true}},
+				Provider:  &types.ServiceProviderConfig{Type: types.ModelProviderType, Options: types.MultiOptions{"model": {"ai/smollm2"}}},
+			},
+		},
+	}
+	cycles, err := FindCycles(project, WithImplicitDependencies)
+	assert.NilError(t, err)
+	assert.Equal(t, len(cycles), 1)
+	assert.Equal(t, cycles[0].String(), "app -[models]-> runner -> app")
+	assert.DeepEqual(t, cycles[0].Path[0].Sources, []EdgeSource{
+		{Kind: types.DependencyModels, Path: tree.NewPath("services", "app", "models", "llm")},
+	})
+}
+
+func TestFindCyclesSuggestionBreaks(t *testing.T) {
+	// b depends on a by links, so only relaxing a -> b breaks all cycles
+	project := &types.Project{
+		Services: types.Services{
+			"a": {
+				Name:      "a",
+				DependsOn: types.DependsOnConfig{"b": {Condition: types.ServiceConditionStarted, Required: true}},
+			},
+			"b": {
+				Name:      "b",
+				Links:     []string{"a:alias"},
+				DependsOn: types.DependsOnConfig{"c": {Condition: types.ServiceConditionStarted, Required: true}},
+			},
+			"c": {
+				Name:      "c",
+				DependsOn: types.DependsOnConfig{"a": {Condition: types.ServiceConditionStarted, Required: true}},
+			},
+		},
+	}
+	cycles, err := FindCycles(project, WithImplicitDependencies)
+	assert.NilError(t, err)
+	assert.Equal(t, len(cycles), 1)
+	assert.Equal(t, cycles[0].String(), "a -> b -[links]-> a")
+	suggestions := cycles[0].Suggestions
+	assert.Equal(t, len(suggestions), 3)
+	assert.Equal(t, suggestions[0].Edge.From, "a")
+	assert.Assert(t, suggestions[0].Breaks)
+	assert.Equal(t, suggestions[1].Edge.From, "b")
+	assert.Assert(t, !suggestions[1].Breaks)
+	assert.Equal(t, suggestions[2].Edge.From, "c")
+	assert.Assert(t, !suggestions[2].Breaks)
+}
+
+func TestLocateCycles(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "compose.yaml")
+	assert.NilError(t, os.WriteFile(filename, []byte(`services:
+  a:
+    depends_on:
+      b:
+        condition: service_healthy
+  b:
+    depends_on:
+      c:
+        condition: service_started
+        required: false
+  c:
+    network_mode: service:a
+`), 0o600))
+	cycles, err := FindCycles(cyclicProject(), WithImplicitDependencies)
+	assert.NilError(t, err)
+	assert.NilError(t, cycles.LocateFiles(filename))
+
+	sources := cycles[0].Path[2].Sources
+	assert.Equal(t, sources[0].String(), "services.c.network_mode ("+filename+":12:5)")
+	sources = cycles[0].Suggestions[0].Edge.Sources
+	assert.Equal(t, sources[0].Line, 8)
+	assert.Equal(t, sources[0].Column, 7)
+}
+
+func TestLocateCyclesShortSyntax(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "compose.yaml")
+	assert.NilError(t, os.WriteFile(filename, []byte(`services:
+  d:
+    depends_on:
+      - e
+      - d
+`), 0o600))
+	cycles, err := FindCycles(cyclicProject())
+	assert.NilError(t, err)
+	assert.NilError(t, cycles.LocateFiles(filename))
+
+	sources := cycles[0].Path[0].Sources
+	assert.Equal(t, sources[0].String(), "services.d.depends_on.d ("+filename+":5:9)")
+}
@@ -0,0 +1,154 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package graph
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/compose-spec/compose-go/v2/utils"
+)
+
+// Graph is a read-only view on project services and their dependencies
+type Graph struct {
+	// Name is the project name
+	Name         string
+	nodes        map[string]Node
+	dependencies map[string][]Edge
+	dependents   map[string][]Edge
+}
+
+// Node is a service in the dependency graph
+type Node struct {
+	Name     string
+	Profiles []string
+	Replicas int
+}
+
+// Edge is a dependency from a service to another one
+type Edge struct {
+	From      string
+	To        string
+	Condition string
+	Required  bool
+	Restart   bool
+	// Kinds lists the attributes declaring the dependency
+	Kinds []types.DependencyKind
+}
+
+// New creates a dependency Graph for project services. WithImplicitDependencies option adds
+// edges for dependencies implied by attributes other than depends_on
+func New(project *types.Project, options ...func(*Options)) (*Graph, error) {
+	o := &Options{}
+	for _, option := range options {
+		option(o)
+	}
+	g, err := newGraph(project, o.implicitDependencies)
+	if err != nil {
+		return nil, err
+	}
+	return newView(project.Name, g, types.ServiceConditionStarted), nil
+}
+
+// newView creates a Graph from the internal graph structure. Edges not declared by depends_on
+// are set implicitCondition
+func newView(name string, g *graph[types.ServiceConfig], implicitCondition string) *Graph {
+	res := &Graph{
+		Name:         name,
+		nodes:        map[string]Node{},
+		dependencies: map[string][]Edge{},
+		dependents:   map[string][]Edge{},
+	}
+	for _, name := range utils.MapKeys(g.vertices) {
+		v := g.vertices[name]
+		res.nodes[name] = Node{
+			Name:     name,
+			Profiles: slices.Clone(v.service.Profiles),
+			Replicas: v.service.GetScale(),
+		}
+		for _, dep := range utils.MapKeys(v.children) {
+			d := types.ServiceDependency{Condition: implicitCondition, Required: true}
+			if slices.Contains(v.kinds[dep], types.DependencyDependsOn) {
+				d = v.service.DependsOn[dep]
+				if d.Condition == "" {
+					d.Condition = types.ServiceConditionStarted
+				}
+			}
+			e := Edge{
+				From:      name,
+				To:        dep,
+				Condition: d.Condition,
+				Required:  d.Required,
+				Restart:   d.Restart,
+				Kinds:     slices.Clone(v.kinds[dep]),
+			}
+			res.dependencies[name] = append(res.dependencies[name], e)
+			res.dependents[dep] = append(res.dependents[dep], e)
+		}
+	}
+	for name := range res.dependents {
+		slices.SortFunc(res.dependents[name], func(a, b Edge) int {
+			return strings.Compare(a.From, b.From)
+		})
+	}
+	return res
+}
+
+// Nodes returns the graph nodes sorted by name
+func (g *Graph) Nodes() []Node {
+	nodes := make([]Node, 0, len(g.nodes))
+	for _, name := range utils.MapKeys(g.nodes) {
+		n, _ := g.Node(name)
+		nodes = append(nodes, n)
+	}
+	return nodes
+}
+
+// Node returns the node for a service
+func (g *Graph) Node(name string) (Node, bool) {
+	n, ok := g.nodes[name]
+	n.Profiles = slices.Clone(n.Profiles)
+	return n, ok
+}
+
+// Edges returns all graph edges, sorted by source then target service
+func (g *Graph) Edges() []Edge {
+	var edges []Edge
+	for _, name := range utils.MapKeys(g.nodes) {
+		edges = append(edges, cloneEdges(g.dependencies[name])...)
+	}
+	return edges
+}
+
+// Dependencies returns the edges from a service to the services it depends on
+func (g *Graph) Dependencies(name string) []Edge {
+	return cloneEdges(g.dependencies[name])
+}
+
+// Dependents returns the edges from services depending on a service
+func (g *Graph) Dependents(name string) []Edge {
+	return cloneEdges(g.dependents[name])
+}
+
+func cloneEdges(edges []Edge) []Edge {
+	res := slices.Clone(edges)
+	for i := range res {
+		res[i].Kinds = slices.Clone(res[i].Kinds)
+	}
+	return res
+}
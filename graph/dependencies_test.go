@@ -0,0 +1,177 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package graph
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func renderProject() *types.Project {
+	scale := 2
+	return &types.Project{
+		Name: "demo",
+		Services: types.Services{
+			"web": {
+				Name:  "web",
+				Scale: &scale,
+				DependsOn: types.DependsOnConfig{
+					"db":    {Condition: types.ServiceConditionHealthy, Required: true, Restart: true},
+					"cache": {Condition: types.ServiceConditionStarted},
+				},
+			},
+			"db": {Name: "db"},
+			"cache": {
+				Name:     "cache",
+				Profiles: []string{"debug"},
+			},
+			"migrate": {
+				Name: "migrate",
+				DependsOn: types.DependsOnConfig{
+					"db": {Condition: types.ServiceConditionCompletedSuccessfully, Required: true},
+				},
+			},
+		},
+	}
+}
+
+func TestNew(t *testing.T) {
+	dependsOn := []types.DependencyKind{types.DependencyDependsOn}
+	g, err := New(renderProject())
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, g.Nodes(), []Node{
+		{Name: "cache", Profiles: []string{"debug"}, Replicas: 1},
+		{Name: "db", Replicas: 1},
+		{Name: "migrate", Replicas: 1},
+		{Name: "web", Replicas: 2},
+	})
+	assert.DeepEqual(t, g.Edges(), []Edge{
+		{From: "migrate", To: "db", Condition: types.ServiceConditionCompletedSuccessfully, Required: true, Kinds: dependsOn},
+		{From: "web", To: "cache", Condition: types.ServiceConditionStarted, Kinds: dependsOn},
+		{From: "web", To: "db", Condition: types.ServiceConditionHealthy, Required: true, Restart: true, Kinds: dependsOn},
+	})
+	assert.DeepEqual(t, g.Dependents("db"), []Edge{
+		{From: "migrate", To: "db", Condition: types.ServiceConditionCompletedSuccessfully, Required: true, Kinds: dependsOn},
+		{From: "web", To: "db", Condition: types.ServiceConditionHealthy, Required: true, Restart: true, Kinds: dependsOn},
+	})
+	assert.Equal(t, len(g.Dependencies("db")), 0)
+
+	n, ok := g.Node("cache")
+	assert.Assert(t, ok)
+	n.Profiles[0] = "changed"
+	n, _ = g.Node("cache")
+	assert.DeepEqual(t, n.Profiles, []string{"debug"})
+	_, ok = g.Node("unknown")
+	assert.Assert(t, !ok)
+}
+
+func TestWriteDOT(t *testing.T) {
+	g, err := New(renderProject())
+	assert.NilError(t, err)
+	var sb strings.Builder
+	assert.NilError(t, g.WriteDOT(&sb))
+	assert.Equal(t, sb.String(), `digraph "demo" {
+  node [shape=box];
+  "cache" [label="cache\nprofiles: debug"];
+  "db" [label="db"];
+  "migrate" [label="migrate"];
+  "web" [label="web\nreplicas: 2"];
+  "migrate" -> "db" [label="service_completed_successfully"];
+  "web" -> "cache" [label="service_started, optional", style=dashed];
+  "web" -> "db" [label="service_healthy, restart"];
+}
+`)
+}
+
+func TestWriteDOTQuoting(t *testing.T) {
+	g, err := New(&types.Project{
+		Name: `caf\"é`,
+		Services: types.Services{
+			"café": {Name: "café", Profiles: []string{"débogage"}},
+		},
+	})
+	assert.NilError(t, err)
+	var sb strings.Builder
+	assert.NilError(t, g.WriteDOT(&sb))
+	assert.Equal(t, sb.String(), `digraph "caf\\\"é" {
+  node [shape=box];
+  "café" [label="café\nprofiles: débogage"];
+}
+`)
+}
+
+func TestWriteMermaid(t *testing.T) {
+	g, err := New(renderProject())
+	assert.NilError(t, err)
+	var sb strings.Builder
+	assert.NilError(t, g.WriteMermaid(&sb))
+	assert.Equal(t, sb.String(), `flowchart TD
+  s0["cache<br/>profiles: debug"]
+  s1["db"]
+  s2["migrate"]
+  s3["web<br/>replicas: 2"]
+  s2 -->|"service_completed_successfully"| s1
+  s3 -.->|"service_started, optional"| s0
+  s3 -->|"service_healthy, restart"| s1
+`)
+}
+
+func TestNewWithImplicitDependencies(t *testing.T) {
+	project := renderProject()
+	project.Services["sidecar"] = types.ServiceConfig{
+		Name:        "sidecar",
+		NetworkMode: "service:web",
+		Pid:         "service:web",
+		VolumesFrom: []string{"db:ro"},
+	}
+	g, err := New(project)
+	assert.NilError(t, err)
+	assert.Equal(t, len(g.Dependencies("sidecar")), 0)
+
+	g, err = New(project, WithImplicitDependencies)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, g.Dependencies("sidecar"), []Edge{
+		{From: "sidecar", To: "db", Condition: types.ServiceConditionStarted, Required: true, Kinds: []types.DependencyKind{types.DependencyVolumesFrom}},
+		{From: "sidecar", To: "web", Condition: types.ServiceConditionStarted, Required: true, Kinds: []types.DependencyKind{types.DependencyNetworkMode, types.DependencyPid}},
+	})
+
+	var sb strings.Builder
+	assert.NilError(t, g.WriteDOT(&sb))
+	assert.Assert(t, strings.Contains(sb.String(), `"sidecar" -> "web" [label="service_started, network_mode, pid"];`), sb.String())
+}
+
+func TestNewWithModelProvider(t *testing.T) {
+	project := renderProject()
+	project.Models = types.Models{"llm": {Model: "ai/smollm2"}}
+	project.Services["runner"] = types.ServiceConfig{
+		Name:     "runner",
+		Provider: &types.ServiceProviderConfig{Type: types.ModelProviderType, Options: types.MultiOptions{"model": {"ai/smollm2"}}},
+	}
+	project.Services["chat"] = types.ServiceConfig{
+		Name:   "chat",
+		Models: map[string]*types.ServiceModelConfig{"llm": {}},
+	}
+	g, err := New(project, WithImplicitDependencies)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, g.Dependencies("chat"), []Edge{
+		{From: "chat", To: "runner", Condition: types.ServiceConditionStarted, Required: true, Kinds: []types.DependencyKind{types.DependencyModels}},
+	})
+}
@@ -16,6 +16,12 @@
 
 package graph
 
+import (
+	"slices"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
 // graph represents project as service dependencies
 type graph[T any] struct {
 	vertices map[string]*vertex[T]
@@ -27,6 +33,8 @@ type vertex[T any] struct {
 	service  *T
 	children map[string]*vertex[T]
 	parents  map[string]*vertex[T]
+	// kinds records the attributes declaring the dependency on each child
+	kinds map[string][]types.DependencyKind
 }
 
 func (g *graph[T]) addVertex(name string, service T) {
@@ -35,12 +43,19 @@ func (g *graph[T]) addVertex(name string, service T) {
 		service:  &service,
 		parents:  map[string]*vertex[T]{},
 		children: map[string]*vertex[T]{},
+		kinds:    map[string][]types.DependencyKind{},
 	}
 }
 
-func (g *graph[T]) addEdge(src, dest string) {
-	g.vertices[src].children[dest] = g.vertices[dest]
-	g.vertices[dest].parents[src] = g.vertices[src]
+func (g *graph[T]) addEdge(src, dest string, kinds ...types.DependencyKind) {
+	v := g.vertices[src]
+	v.children[dest] = g.vertices[dest]
+	g.vertices[dest].parents[src] = v
+	for _, kind := range kinds {
+		if !slices.Contains(v.kinds[dest], kind) {
+			v.kinds[dest] = append(v.kinds[dest], kind)
+		}
+	}
 }
 
 func (g *graph[T]) roots() []*vertex[T] {
@@ -305,7 +305,7 @@ func TestBuildGraph(t *testing.T) {
 				DisabledServices: tC.disabled,
 			}
 
-			graph, err := newGraph(&project)
+			graph, err := newGraph(&project, false)
 			if tC.expectedError != "" {
 				assert.Error(t, err, tC.expectedError)
 				return
@@ -328,6 +328,54 @@ func Test_detectCycle(t *testing.T) {
 	assert.Error(t, err, "dependency cycle detected: B -> D -> C -> B")
 }
 
+func TestCheckCycleWithImplicitDependencies(t *testing.T) {
+	project := &types.Project{
+		Services: types.Services{
+			"app": {
+				Name:      "app",
+				DependsOn: types.DependsOnConfig{"proxy": {Condition: types.ServiceConditionStarted, Required: true}},
+			},
+			"proxy": {
+				Name:        "proxy",
+				NetworkMode: "service:app",
+				Links:       []string{"app:backend"},
+			},
+		},
+	}
+	assert.NilError(t, CheckCycle(project))
+	assert.Error(t, CheckCycle(project, WithImplicitDependencies),
+		"dependency cycle detected: app -> proxy -[network_mode,links]-> app")
+}
+
+func TestInDependencyOrderWithImplicitDependencies(t *testing.T) {
+	project := &types.Project{
+		Services: types.Services{
+			"app": {
+				Name:  "app",
+				Build: &types.BuildConfig{AdditionalContexts: types.Mapping{"base": "service:base"}},
+			},
+			"base": {Name: "base"},
+			"debug": {
+				Name: "debug",
+				Ipc:  "service:app",
+			},
+		},
+	}
+	var order []string
+	err := InDependencyOrder(context.Background(), project, func(_ context.Context, name string, _ types.ServiceConfig) error {
+		order = append(order, name)
+		return nil
+	}, WithImplicitDependencies, WithMaxConcurrency(1))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, order, []string{"base", "app", "debug"})
+
+	delete(project.Services, "base")
+	err = InDependencyOrder(context.Background(), project, func(context.Context, string, types.ServiceConfig) error {
+		return nil
+	}, WithImplicitDependencies)
+	assert.Error(t, err, `service "app" depends on unknown service "base"`)
+}
+
 func TestWith_RootNodesAndUp(t *testing.T) {
 	graph := exampleGraph()
 
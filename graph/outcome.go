@@ -0,0 +1,273 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// FailurePolicy defines how a traversal reacts to a visitor failure
+type FailurePolicy int
+
+const (
+	// FailFast cancels the traversal on first failure
+	FailFast FailurePolicy = iota
+	// ContinueOnFailure visits all nodes, regardless of failures
+	ContinueOnFailure
+	// SkipDependents skips nodes which depend, directly or not, on a failed node, and visit all others
+	SkipDependents
+)
+
+// OutcomeStatus is the final state of a node once traversal completed
+type OutcomeStatus string
+
+const (
+	OutcomeSucceeded OutcomeStatus = "succeeded"
+	OutcomeFailed    OutcomeStatus = "failed"
+	OutcomeSkipped   OutcomeStatus = "skipped"
+)
+
+var (
+	// ErrDependencyFailed is reported by nodes skipped as a dependency failed
+	ErrDependencyFailed = errors.New("dependency failed")
+	// ErrNotVisited is reported by nodes not reached before traversal was interrupted
+	ErrNotVisited = errors.New("not visited")
+)
+
+// Outcome is the result of visiting a node
+type Outcome[T any] struct {
+	Status OutcomeStatus
+	Result T
+	// Err is the last visitor error for a failed node, or the reason a node was skipped
+	Err error
+	// Attempts is the number of times the visitor ran
+	Attempts int
+	// Duration is the total time spent visiting node, including retries
+	Duration time.Duration
+}
+
+// EventType is the kind of traversal Event
+type EventType string
+
+const (
+	EventStarted  EventType = "started"
+	EventFinished EventType = "finished"
+	EventRetrying EventType = "retrying"
+	EventFailed   EventType = "failed"
+	EventSkipped  EventType = "skipped"
+)
+
+// Event reports progress walking the graph
+type Event struct {
+	Type    EventType
+	Service string
+	// Attempt is the attempt number, starting at 1
+	Attempt int
+	// Duration is the time spent on the attempt for finished, retrying and failed events
+	Duration time.Duration
+	Err      error
+}
+
+// Observer receives traversal events. Events are emitted concurrently while walking the graph
+type Observer interface {
+	OnEvent(Event)
+}
+
+// ObserverFunc adapts a func as an Observer
+type ObserverFunc func(Event)
+
+func (f ObserverFunc) OnEvent(e Event) {
+	f(e)
+}
+
+// WithFailurePolicy configure traversal reaction to visitor failures
+func WithFailurePolicy(policy FailurePolicy) func(*Options) {
+	return func(o *Options) {
+		o.failurePolicy = policy
+	}
+}
+
+// WithTimeout configure traversal to limit time spent on each visitor attempt
+func WithTimeout(timeout time.Duration) func(*Options) {
+	return func(o *Options) {
+		o.timeout = timeout
+	}
+}
+
+// WithRetry configure traversal to retry failed visitors, waiting for backoff before first retry
+// then doubling delay for each subsequent one
+func WithRetry(retries int, backoff time.Duration) func(*Options) {
+	return func(o *Options) {
+		o.retries = retries
+		o.backoff = backoff
+	}
+}
+
+// WithObserver configure traversal to report progress to observer
+func WithObserver(observer Observer) func(*Options) {
+	return func(o *Options) {
+		o.observer = observer
+	}
+}
+
+// WalkInDependencyOrder walk the service graph and invoke CollectorFn in respect to dependency order, then
+// return the outcome for each service. Visitor failures are reported by outcomes, error is only set
+// when the service graph can't be built
+func WalkInDependencyOrder[T any](ctx context.Context, project *types.Project, fn CollectorFn[types.ServiceConfig, T], options ...func(*Options)) (map[string]Outcome[T], error) {
+	t := newTraversal(fn)
+	for _, option := range options {
+		option(t.Options)
+	}
+	graph, err := newGraph(project, t.implicitDependencies)
+	if err != nil {
+		return nil, err
+	}
+	_ = walk(ctx, graph, t)
+	for name := range graph.vertices {
+		if _, ok := t.outcomes[name]; !ok {
+			t.outcomes[name] = Outcome[T]{Status: OutcomeSkipped, Err: ErrNotVisited}
+			t.notify(Event{Type: EventSkipped, Service: name, Err: ErrNotVisited})
+		}
+	}
+	return t.outcomes, nil
+}
+
+// run visits node according to traversal options and records the outcome
+func (t *traversal[S, T]) run(ctx context.Context, node *vertex[S]) (T, error) {
+	var zero T
+	if t.skip(node) {
+		t.record(node.key, Outcome[T]{Status: OutcomeSkipped})
+		t.notify(Event{Type: EventSkipped, Service: node.key})
+		return zero, nil
+	}
+	if t.failurePolicy == SkipDependents {
+		if dep := t.failedDependency(node); dep != "" {
+			err := fmt.Errorf("%w: %s", ErrDependencyFailed, dep)
+			t.record(node.key, Outcome[T]{Status: OutcomeSkipped, Err: err})
+			t.notify(Event{Type: EventSkipped, Service: node.key, Err: err})
+			return zero, nil
+		}
+	}
+
+	start := time.Now()
+	backoff := t.backoff
+	for attempt := 1; ; attempt++ {
+		t.notify(Event{Type: EventStarted, Service: node.key, Attempt: attempt})
+		attemptStart := time.Now()
+		result, err := t.attempt(ctx, node)
+		elapsed := time.Since(attemptStart)
+		if err == nil {
+			t.record(node.key, Outcome[T]{Status: OutcomeSucceeded, Result: result, Attempts: attempt, Duration: time.Since(start)})
+			t.notify(Event{Type: EventFinished, Service: node.key, Attempt: attempt, Duration: elapsed})
+			return result, nil
+		}
+		if attempt <= t.retries && ctx.Err() == nil {
+			t.notify(Event{Type: EventRetrying, Service: node.key, Attempt: attempt, Duration: elapsed, Err: err})
+			select {
+			case <-time.After(backoff):
+				backoff *= 2
+				continue
+			case <-ctx.Done():
+			}
+		}
+		t.record(node.key, Outcome[T]{Status: OutcomeFailed, Result: result, Err: err, Attempts: attempt, Duration: time.Since(start)})
+		t.notify(Event{Type: EventFailed, Service: node.key, Attempt: attempt, Duration: elapsed, Err: err})
+		return result, err
+	}
+}
+
+// attempt runs visitor once, giving up when timeout is reached. A visitor ignoring context cancellation
+// keeps running in background after the attempt timed out
+func (t *traversal[S, T]) attempt(ctx context.Context, node *vertex[S]) (T, error) {
+	if t.timeout <= 0 {
+		return t.visitor(ctx, node.key, *node.service)
+	}
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	defer cancel()
+
+	type visit struct {
+		result T
+		err    error
+	}
+	ch := make(chan visit, 1)
+	go func() {
+		result, err := t.visitor(ctx, node.key, *node.service)
+		ch <- visit{result: result, err: err}
+	}()
+	select {
+	case v := <-ch:
+		return v.result, v.err
+	case <-ctx.Done():
+		var zero T
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return zero, fmt.Errorf("service %q timed out after %s: %w", node.key, t.timeout, ctx.Err())
+		}
+		return zero, ctx.Err()
+	}
+}
+
+// failedDependency returns the name of a node visited before node which failed or was skipped for this reason
+func (t *traversal[S, T]) failedDependency(node *vertex[S]) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	depends := node.children
+	if t.inverse {
+		depends = node.parents
+	}
+	for name := range depends {
+		o := t.outcomes[name]
+		if o.Status == OutcomeFailed || errors.Is(o.Err, ErrDependencyFailed) {
+			return name
+		}
+	}
+	return ""
+}
+
+// err joins errors reported by failed nodes and nodes skipped as a dependency failed
+func (t *traversal[S, T]) err() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var errs []error
+	for _, name := range slices.Sorted(maps.Keys(t.outcomes)) {
+		o := t.outcomes[name]
+		if o.Err != nil && o.Status != OutcomeSucceeded {
+			errs = append(errs, fmt.Errorf("service %q %s: %w", name, o.Status, o.Err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (t *traversal[S, T]) record(name string, outcome Outcome[T]) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.outcomes[name] = outcome
+}
+
+func (t *traversal[S, T]) notify(e Event) {
+	if t.observer != nil {
+		t.observer.OnEvent(e)
+	}
+}
@@ -0,0 +1,182 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func failureProject() *types.Project {
+	return &types.Project{
+		Services: types.Services{
+			"db":    {Name: "db"},
+			"cache": {Name: "cache"},
+			"web": {
+				Name:      "web",
+				DependsOn: types.DependsOnConfig{"db": {Condition: types.ServiceConditionStarted, Required: true}},
+			},
+			"worker": {
+				Name:      "worker",
+				DependsOn: types.DependsOnConfig{"web": {Condition: types.ServiceConditionStarted, Required: true}},
+			},
+		},
+	}
+}
+
+func failOn(service string) CollectorFn[types.ServiceConfig, string] {
+	return func(_ context.Context, name string, _ types.ServiceConfig) (string, error) {
+		if name == service {
+			return "", errors.New("boom")
+		}
+		return name, nil
+	}
+}
+
+func statuses[T any](outcomes map[string]Outcome[T]) map[string]OutcomeStatus {
+	res := map[string]OutcomeStatus{}
+	for name, o := range outcomes {
+		res[name] = o.Status
+	}
+	return res
+}
+
+func TestWalkSkipDependents(t *testing.T) {
+	outcomes, err := WalkInDependencyOrder(context.Background(), failureProject(), failOn("db"), WithFailurePolicy(SkipDependents))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, statuses(outcomes), map[string]OutcomeStatus{
+		"db":     OutcomeFailed,
+		"cache":  OutcomeSucceeded,
+		"web":    OutcomeSkipped,
+		"worker": OutcomeSkipped,
+	})
+	assert.Error(t, outcomes["db"].Err, "boom")
+	assert.Equal(t, outcomes["cache"].Result, "cache")
+	assert.Error(t, outcomes["web"].Err, "dependency failed: db")
+	assert.Error(t, outcomes["worker"].Err, "dependency failed: web")
+}
+
+func TestWalkContinueOnFailure(t *testing.T) {
+	outcomes, err := WalkInDependencyOrder(context.Background(), failureProject(), failOn("db"), WithFailurePolicy(ContinueOnFailure))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, statuses(outcomes), map[string]OutcomeStatus{
+		"db":     OutcomeFailed,
+		"cache":  OutcomeSucceeded,
+		"web":    OutcomeSucceeded,
+		"worker": OutcomeSucceeded,
+	})
+}
+
+func TestWalkFailFast(t *testing.T) {
+	outcomes, err := WalkInDependencyOrder(context.Background(), failureProject(), failOn("db"))
+	assert.NilError(t, err)
+	assert.Equal(t, outcomes["db"].Status, OutcomeFailed)
+	assert.Equal(t, outcomes["web"].Status, OutcomeSkipped)
+	assert.Assert(t, errors.Is(outcomes["web"].Err, ErrNotVisited))
+	assert.Equal(t, outcomes["worker"].Status, OutcomeSkipped)
+}
+
+func TestInDependencyOrderFailurePolicies(t *testing.T) {
+	visitor := func(ctx context.Context, name string, config types.ServiceConfig) error {
+		_, err := failOn("db")(ctx, name, config)
+		return err
+	}
+	tests := []struct {
+		policy   FailurePolicy
+		expected string
+	}{
+		{
+			policy:   ContinueOnFailure,
+			expected: `service "db" failed: boom`,
+		},
+		{
+			policy:   SkipDependents,
+			expected: "service \"db\" failed: boom\nservice \"web\" skipped: dependency failed: db\nservice \"worker\" skipped: dependency failed: web",
+		},
+	}
+	for _, tt := range tests {
+		err := InDependencyOrder(context.Background(), failureProject(), visitor, WithFailurePolicy(tt.policy))
+		assert.Error(t, err, tt.expected)
+	}
+
+	err := InDependencyOrder(context.Background(), failureProject(), func(context.Context, string, types.ServiceConfig) error {
+		return nil
+	}, WithFailurePolicy(SkipDependents))
+	assert.NilError(t, err)
+}
+
+func TestWalkRetry(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		events []string
+	)
+	observer := ObserverFunc(func(e Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		if e.Service == "db" {
+			events = append(events, fmt.Sprintf("%s %d %v", e.Type, e.Attempt, e.Err))
+		}
+	})
+	failures := 2
+	fn := func(_ context.Context, name string, _ types.ServiceConfig) (string, error) {
+		if name == "db" && failures > 0 {
+			failures--
+			return "", errors.New("not yet")
+		}
+		return name, nil
+	}
+	outcomes, err := WalkInDependencyOrder(context.Background(), failureProject(), fn,
+		WithRetry(2, time.Millisecond), WithObserver(observer))
+	assert.NilError(t, err)
+	assert.Equal(t, outcomes["db"].Status, OutcomeSucceeded)
+	assert.Equal(t, outcomes["db"].Attempts, 3)
+	assert.Equal(t, outcomes["worker"].Status, OutcomeSucceeded)
+
+	assert.DeepEqual(t, events, []string{
+		"started 1 <nil>",
+		"retrying 1 not yet",
+		"started 2 <nil>",
+		"retrying 2 not yet",
+		"started 3 <nil>",
+		"finished 3 <nil>",
+	})
+}
+
+func TestWalkTimeout(t *testing.T) {
+	fn := func(ctx context.Context, name string, _ types.ServiceConfig) (string, error) {
+		if name == "db" {
+			<-ctx.Done()
+			return "", ctx.Err()
+		}
+		return name, nil
+	}
+	outcomes, err := WalkInDependencyOrder(context.Background(), failureProject(), fn,
+		WithTimeout(10*time.Millisecond), WithFailurePolicy(SkipDependents))
+	assert.NilError(t, err)
+	assert.Equal(t, outcomes["db"].Status, OutcomeFailed)
+	assert.Assert(t, errors.Is(outcomes["db"].Err, context.DeadlineExceeded))
+	assert.Error(t, outcomes["db"].Err, `service "db" timed out after 10ms: context deadline exceeded`)
+	assert.Equal(t, outcomes["cache"].Status, OutcomeSucceeded)
+	assert.Equal(t, outcomes["web"].Status, OutcomeSkipped)
+}
@@ -0,0 +1,187 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package graph
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/compose-spec/compose-go/v2/utils"
+)
+
+// Docker engine defaults applied to healthcheck attributes which are not set
+const (
+	DefaultHealthCheckInterval      = 30 * time.Second
+	DefaultHealthCheckTimeout       = 30 * time.Second
+	DefaultHealthCheckRetries       = 3
+	DefaultHealthCheckStartInterval = 5 * time.Second
+)
+
+// Readiness is an estimation of the time required to get project services ready.
+// Container creation, startup and one-shot services are considered instantaneous, so that only
+// healthchecks contribute to the estimated durations
+type Readiness struct {
+	// Services is the readiness estimation per service
+	Services map[string]ServiceReadiness
+	// CriticalPath is the chain of dependencies, from the first service started to the last one
+	// getting ready, which determines the worst-case project readiness
+	CriticalPath []string
+	// Duration is the worst-case time for all services to be ready
+	Duration time.Duration
+	// Layers groups services which can be started in parallel, in startup order
+	Layers [][]string
+	// Warnings reports dependencies which can't be honored as declared
+	Warnings []string
+}
+
+// ServiceReadiness is the readiness estimation for a service, relative to project startup
+type ServiceReadiness struct {
+	// Start is the earliest time the service can be started
+	Start time.Duration
+	// WorstStart is the latest time the service is started, assuming all healthchecks pass
+	WorstStart time.Duration
+	// Ready is the earliest time the service is ready
+	Ready time.Duration
+	// WorstReady is the latest time the service is ready, assuming its healthcheck passes
+	WorstReady time.Duration
+	// Layer is the index of the startup layer the service belongs to
+	Layer int
+}
+
+// HealthCheckTimings returns the earliest and worst-case delay for a healthcheck to report a healthy
+// status once container has started. ok is false if service has no healthcheck or it is disabled.
+//
+// The earliest delay is the first probe. The worst case is the last probe which can succeed before the
+// container is declared unhealthy, i.e. after the start period then retries failed probes which
+// each ran until timeout
+func HealthCheckTimings(healthcheck *types.HealthCheckConfig) (earliest, worst time.Duration, ok bool) {
+	if healthcheck == nil || healthcheck.Disable || (len(healthcheck.Test) > 0 && healthcheck.Test[0] == "NONE") {
+		return 0, 0, false
+	}
+	interval := durationOr(healthcheck.Interval, DefaultHealthCheckInterval)
+	timeout := durationOr(healthcheck.Timeout, DefaultHealthCheckTimeout)
+	startPeriod := durationOr(healthcheck.StartPeriod, 0)
+	startInterval := durationOr(healthcheck.StartInterval, DefaultHealthCheckStartInterval)
+	retries := uint64(DefaultHealthCheckRetries)
+	if healthcheck.Retries != nil && *healthcheck.Retries > 0 {
+		retries = *healthcheck.Retries
+	}
+
+	earliest = interval
+	if startPeriod > 0 {
+		earliest = min(startInterval, startPeriod)
+	}
+	worst = startPeriod + time.Duration(retries)*(interval+timeout)
+	return earliest, worst, true
+}
+
+func durationOr(d *types.Duration, defaultValue time.Duration) time.Duration {
+	if d == nil || *d <= 0 {
+		return defaultValue
+	}
+	return time.Duration(*d)
+}
+
+// EstimateReadiness computes the time required for project services to be ready, based on
+// depends_on conditions and healthcheck configuration
+func EstimateReadiness(project *types.Project, options ...func(*Options)) (*Readiness, error) {
+	o := &Options{}
+	for _, option := range options {
+		option(o)
+	}
+	g, err := newGraph(project, o.implicitDependencies)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &Readiness{
+		Services: map[string]ServiceReadiness{},
+	}
+	// predecessor records the dependency determining the worst-case start of a service
+	predecessor := map[string]string{}
+	var estimate func(v *vertex[types.ServiceConfig]) ServiceReadiness
+	estimate = func(v *vertex[types.ServiceConfig]) ServiceReadiness {
+		if s, ok := r.Services[v.key]; ok {
+			return s
+		}
+		var s ServiceReadiness
+		for _, dep := range utils.MapKeys(v.children) {
+			d := estimate(v.children[dep])
+			s.Layer = max(s.Layer, d.Layer+1)
+
+			start, worstStart := d.Start, d.WorstStart
+			if v.service.DependsOn[dep].Condition == types.ServiceConditionHealthy {
+				if _, _, ok := HealthCheckTimings(v.children[dep].service.HealthCheck); !ok {
+					r.Warnings = append(r.Warnings, fmt.Sprintf("service %q depends on %q being healthy, but %q has no healthcheck", v.key, dep, dep))
+				}
+				start, worstStart = d.Ready, d.WorstReady
+			}
+			s.Start = max(s.Start, start)
+			if worstStart > s.WorstStart || predecessor[v.key] == "" {
+				s.WorstStart = max(s.WorstStart, worstStart)
+				predecessor[v.key] = dep
+			}
+		}
+		s.Ready, s.WorstReady = s.Start, s.WorstStart
+		if earliest, worst, ok := HealthCheckTimings(v.service.HealthCheck); ok {
+			s.Ready += earliest
+			s.WorstReady += worst
+		}
+		r.Services[v.key] = s
+		return s
+	}
+
+	last := ""
+	for _, name := range utils.MapKeys(g.vertices) {
+		s := estimate(g.vertices[name])
+		for len(r.Layers) <= s.Layer {
+			r.Layers = append(r.Layers, nil)
+		}
+		r.Layers[s.Layer] = append(r.Layers[s.Layer], name)
+		if last == "" || s.WorstReady > r.Duration {
+			r.Duration = s.WorstReady
+			last = name
+		}
+	}
+	for name := last; name != ""; name = predecessor[name] {
+		r.CriticalPath = append(r.CriticalPath, name)
+	}
+	slices.Reverse(r.CriticalPath)
+	slices.Sort(r.Warnings)
+	return r, nil
+}
+
+// String renders the readiness estimation as a human-readable report
+func (r *Readiness) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "worst-case readiness: %s\n", r.Duration)
+	fmt.Fprintf(&sb, "critical path: %s\n", strings.Join(r.CriticalPath, " -> "))
+	for i, layer := range r.Layers {
+		fmt.Fprintf(&sb, "layer %d:\n", i)
+		for _, name := range layer {
+			s := r.Services[name]
+			fmt.Fprintf(&sb, "  %s: start %s-%s, ready %s-%s\n", name, s.Start, s.WorstStart, s.Ready, s.WorstReady)
+		}
+	}
+	for _, w := range r.Warnings {
+		fmt.Fprintf(&sb, "warning: %s\n", w)
+	}
+	return sb.String()
+}
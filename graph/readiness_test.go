@@ -0,0 +1,124 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package graph
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func duration(d time.Duration) *types.Duration {
+	v := types.Duration(d)
+	return &v
+}
+
+func TestHealthCheckTimings(t *testing.T) {
+	retries := uint64(5)
+	earliest, worst, ok := HealthCheckTimings(&types.HealthCheckConfig{
+		Test:     types.HealthCheckTest{"CMD", "true"},
+		Interval: duration(10 * time.Second),
+		Timeout:  duration(2 * time.Second),
+		Retries:  &retries,
+	})
+	assert.Assert(t, ok)
+	assert.Equal(t, earliest, 10*time.Second)
+	assert.Equal(t, worst, 60*time.Second)
+
+	earliest, worst, ok = HealthCheckTimings(&types.HealthCheckConfig{
+		StartPeriod: duration(time.Minute),
+	})
+	assert.Assert(t, ok)
+	assert.Equal(t, earliest, DefaultHealthCheckStartInterval)
+	assert.Equal(t, worst, time.Minute+3*time.Minute)
+
+	_, _, ok = HealthCheckTimings(&types.HealthCheckConfig{Test: types.HealthCheckTest{"NONE"}})
+	assert.Assert(t, !ok)
+	_, _, ok = HealthCheckTimings(&types.HealthCheckConfig{Disable: true})
+	assert.Assert(t, !ok)
+	_, _, ok = HealthCheckTimings(nil)
+	assert.Assert(t, !ok)
+}
+
+func TestEstimateReadiness(t *testing.T) {
+	retries := uint64(3)
+	project := &types.Project{
+		Services: types.Services{
+			"db": {
+				Name: "db",
+				HealthCheck: &types.HealthCheckConfig{
+					Test:          types.HealthCheckTest{"CMD", "pg_isready"},
+					Interval:      duration(10 * time.Second),
+					Timeout:       duration(5 * time.Second),
+					Retries:       &retries,
+					StartPeriod:   duration(30 * time.Second),
+					StartInterval: duration(2 * time.Second),
+				},
+			},
+			"cache": {Name: "cache"},
+			"web": {
+				Name: "web",
+				DependsOn: types.DependsOnConfig{
+					"db":    {Condition: types.ServiceConditionHealthy, Required: true},
+					"cache": {Condition: types.ServiceConditionHealthy, Required: true},
+				},
+				HealthCheck: &types.HealthCheckConfig{
+					Test: types.HealthCheckTest{"CMD", "curl", "localhost"},
+				},
+			},
+			"worker": {
+				Name: "worker",
+				DependsOn: types.DependsOnConfig{
+					"web": {Condition: types.ServiceConditionStarted, Required: true},
+				},
+			},
+			"proxy": {
+				Name: "proxy",
+				DependsOn: types.DependsOnConfig{
+					"web": {Condition: types.ServiceConditionHealthy, Required: true},
+				},
+			},
+		},
+	}
+	r, err := EstimateReadiness(project)
+	assert.NilError(t, err)
+
+	assert.DeepEqual(t, r.Layers, [][]string{{"cache", "db"}, {"web"}, {"proxy", "worker"}})
+	assert.DeepEqual(t, r.Services["db"], ServiceReadiness{Ready: 2 * time.Second, WorstReady: 75 * time.Second})
+	assert.DeepEqual(t, r.Services["web"], ServiceReadiness{
+		Start:      2 * time.Second,
+		WorstStart: 75 * time.Second,
+		Ready:      32 * time.Second,
+		WorstReady: 255 * time.Second,
+		Layer:      1,
+	})
+	assert.DeepEqual(t, r.Services["worker"], ServiceReadiness{
+		Start:      2 * time.Second,
+		WorstStart: 75 * time.Second,
+		Ready:      2 * time.Second,
+		WorstReady: 75 * time.Second,
+		Layer:      2,
+	})
+	assert.Equal(t, r.Services["proxy"].WorstReady, 255*time.Second)
+	assert.Equal(t, r.Duration, 255*time.Second)
+	assert.DeepEqual(t, r.CriticalPath, []string{"db", "web", "proxy"})
+	assert.DeepEqual(t, r.Warnings, []string{`service "web" depends on "cache" being healthy, but "cache" has no healthcheck`})
+	assert.Assert(t, strings.Contains(r.String(), "critical path: db -> web -> proxy\n"), r.String())
+}
@@ -0,0 +1,118 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package graph
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// WriteDOT renders the graph using the Graphviz DOT language. Optional dependencies are
+// rendered as dashed edges
+func (g *Graph) WriteDOT(w io.Writer) error {
+	var sb strings.Builder
+	name := g.Name
+	if name == "" {
+		name = "compose"
+	}
+	fmt.Fprintf(&sb, "digraph %s {\n", dotQuote(name))
+	sb.WriteString("  node [shape=box];\n")
+	for _, n := range g.Nodes() {
+		fmt.Fprintf(&sb, "  %s [label=%s];\n", dotQuote(n.Name), dotQuote(nodeLabel(n)...))
+	}
+	for _, e := range g.Edges() {
+		attrs := []string{"label=" + dotQuote(edgeLabel(e))}
+		if !e.Required {
+			attrs = append(attrs, "style=dashed")
+		}
+		fmt.Fprintf(&sb, "  %s -> %s [%s];\n", dotQuote(e.From), dotQuote(e.To), strings.Join(attrs, ", "))
+	}
+	sb.WriteString("}\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteMermaid renders the graph as a Mermaid flowchart. Optional dependencies are
+// rendered as dotted links
+func (g *Graph) WriteMermaid(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("flowchart TD\n")
+	// service names can't be safely used as mermaid identifiers
+	ids := map[string]string{}
+	for i, n := range g.Nodes() {
+		ids[n.Name] = fmt.Sprintf("s%d", i)
+		fmt.Fprintf(&sb, "  %s[%s]\n", ids[n.Name], mermaidQuote(strings.Join(nodeLabel(n), "<br/>")))
+	}
+	for _, e := range g.Edges() {
+		arrow := "-->"
+		if !e.Required {
+			arrow = "-.->"
+		}
+		fmt.Fprintf(&sb, "  %s %s|%s| %s\n", ids[e.From], arrow, mermaidQuote(edgeLabel(e)), ids[e.To])
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func nodeLabel(n Node) []string {
+	label := []string{n.Name}
+	if n.Replicas != 1 {
+		label = append(label, fmt.Sprintf("replicas: %d", n.Replicas))
+	}
+	if len(n.Profiles) > 0 {
+		label = append(label, "profiles: "+strings.Join(n.Profiles, ", "))
+	}
+	return label
+}
+
+func edgeLabel(e Edge) string {
+	var label []string
+	if e.Condition != "" {
+		label = append(label, e.Condition)
+	}
+	for _, kind := range e.Kinds {
+		if kind != types.DependencyDependsOn {
+			label = append(label, string(kind))
+		}
+	}
+	if e.Restart {
+		label = append(label, "restart")
+	}
+	if !e.Required {
+		label = append(label, "optional")
+	}
+	return strings.Join(label, ", ")
+}
+
+var dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+// dotQuote renders lines as a DOT quoted string, lines being separated by DOT \n escape sequence.
+// Only backslash and double quote are escaped, as DOT accepts any other UTF-8 character
+func dotQuote(lines ...string) string {
+	escaped := make([]string, len(lines))
+	for i, l := range lines {
+		escaped[i] = dotEscaper.Replace(l)
+	}
+	return `"` + strings.Join(escaped, `\n`) + `"`
+}
+
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
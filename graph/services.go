@@ -21,6 +21,7 @@ import (
 	"fmt"
 
 	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/compose-spec/compose-go/v2/utils"
 )
 
 // InDependencyOrder walk the service graph an invoke VisitorFn in respect to dependency order
@@ -31,22 +32,36 @@ func InDependencyOrder(ctx context.Context, project *types.Project, fn VisitorFn
 	return err
 }
 
-// CollectInDependencyOrder walk the service graph an invoke CollectorFn in respect to dependency order, then return result for each call
+// CollectInDependencyOrder walk the service graph an invoke CollectorFn in respect to dependency order, then return result for each call.
+// With ContinueOnFailure or SkipDependents failure policy, the returned error joins all failed and skipped services once the walk completed
 func CollectInDependencyOrder[T any](ctx context.Context, project *types.Project, fn CollectorFn[types.ServiceConfig, T], options ...func(*Options)) (map[string]T, error) {
-	graph, err := newGraph(project)
-	if err != nil {
-		return nil, err
-	}
 	t := newTraversal(fn)
 	for _, option := range options {
 		option(t.Options)
 	}
-	err = walk(ctx, graph, t)
-	return t.results, err
+	graph, err := newGraph(project, t.implicitDependencies)
+	if err != nil {
+		return nil, err
+	}
+	if err := walk(ctx, graph, t); err != nil {
+		return t.results, err
+	}
+	return t.results, t.err()
+}
+
+// newGraph creates a service graph from project. When implicit is set, dependencies implied by attributes
+// other than depends_on are also added as edges
+func newGraph(project *types.Project, implicit bool) (*graph[types.ServiceConfig], error) {
+	g, err := buildGraph(project, implicit)
+	if err != nil {
+		return nil, err
+	}
+	err = g.checkCycle()
+	return g, err
 }
 
-// newGraph creates a service graph from project
-func newGraph(project *types.Project) (*graph[types.ServiceConfig], error) {
+// buildGraph creates a service graph from project, without checking for dependency cycles
+func buildGraph(project *types.Project, implicit bool) (*graph[types.ServiceConfig], error) {
 	g := &graph[types.ServiceConfig]{
 		vertices: map[string]*vertex[types.ServiceConfig]{},
 	}
@@ -56,10 +71,8 @@ func newGraph(project *types.Project) (*graph[types.ServiceConfig], error) {
 	}
 
 	for name, s := range project.Services {
-		src := g.vertices[name]
 		for dep, condition := range s.DependsOn {
-			dest, ok := g.vertices[dep]
-			if !ok {
+			if _, ok := g.vertices[dep]; !ok {
 				if condition.Required {
 					if ds, exists := project.DisabledServices[dep]; exists {
 						return nil, fmt.Errorf("service %q is required by %q but is disabled. Can be enabled by profiles %s", dep, name, ds.Profiles)
@@ -70,11 +83,22 @@ func newGraph(project *types.Project) (*graph[types.ServiceConfig], error) {
 				project.Services[name] = s
 				continue
 			}
-			src.children[dep] = dest
-			dest.parents[name] = src
+			g.addEdge(name, dep, types.DependencyDependsOn)
+		}
+		if !implicit {
+			continue
+		}
+		implicitDependencies := project.ImplicitDependencies(s)
+		for _, dep := range utils.MapKeys(implicitDependencies) {
+			if _, ok := g.vertices[dep]; !ok {
+				if ds, exists := project.DisabledServices[dep]; exists {
+					return nil, fmt.Errorf("service %q is required by %q but is disabled. Can be enabled by profiles %s", dep, name, ds.Profiles)
+				}
+				return nil, fmt.Errorf("service %q depends on unknown service %q", name, dep)
+			}
+			g.addEdge(name, dep, implicitDependencies[dep]...)
 		}
 	}
 
-	err := g.checkCycle()
-	return g, err
+	return g, nil
 }
@@ -20,6 +20,7 @@ import (
 	"context"
 	"slices"
 	"sync"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 )
@@ -34,9 +35,10 @@ type traversal[S any, T any] struct {
 	*Options
 	visitor CollectorFn[S, T]
 
-	mu      sync.Mutex
-	status  map[string]int
-	results map[string]T
+	mu       sync.Mutex
+	status   map[string]int
+	results  map[string]T
+	outcomes map[string]Outcome[T]
 }
 
 type Options struct {
@@ -46,6 +48,18 @@ type Options struct {
 	maxConcurrency int
 	// after marks a set of node as starting points walking the graph
 	after []string
+	// implicitDependencies adds dependencies implied by attributes other than depends_on to the graph
+	implicitDependencies bool
+	// failurePolicy defines how traversal reacts to a visitor failure
+	failurePolicy FailurePolicy
+	// timeout limits time spent on each visitor attempt
+	timeout time.Duration
+	// retries is the number of times a failed visitor is retried
+	retries int
+	// backoff is the delay before first retry, doubled for each subsequent one
+	backoff time.Duration
+	// observer receives progress events
+	observer Observer
 }
 
 const (
@@ -55,10 +69,11 @@ const (
 
 func newTraversal[S, T any](fn CollectorFn[S, T]) *traversal[S, T] {
 	return &traversal[S, T]{
-		Options: &Options{},
-		status:  map[string]int{},
-		results: map[string]T{},
-		visitor: fn,
+		Options:  &Options{},
+		status:   map[string]int{},
+		results:  map[string]T{},
+		outcomes: map[string]Outcome[T]{},
+		visitor:  fn,
 	}
 }
 
@@ -74,6 +89,12 @@ func InReverseOrder(o *Options) {
 	o.inverse = true
 }
 
+// WithImplicitDependencies configure the graph to include dependencies implied by network_mode, ipc, pid,
+// volumes_from, links, build additional contexts and models, see types.Project.ImplicitDependencies
+func WithImplicitDependencies(o *Options) {
+	o.implicitDependencies = true
+}
+
 // WithRootNodesAndDown creates a graphTraversal to start from selected nodes
 func WithRootNodesAndDown(nodes []string) func(*Options) {
 	return func(o *Options) {
@@ -131,16 +152,14 @@ func (t *traversal[S, T]) visit(ctx context.Context, eg *errgroup.Group, node *v
 		return
 	}
 	eg.Go(func() error {
-		var (
-			err    error
-			result T
-		)
-		if !t.skip(node) {
-			result, err = t.visitor(ctx, node.key, *node.service)
-		}
+		result, err := t.run(ctx, node)
 		t.done(node, result)
+		if err != nil && t.failurePolicy == FailFast {
+			// don't let adjacent nodes be visited, errgroup will cancel the traversal
+			return err
+		}
 		nodeCh <- node
-		return err
+		return nil
 	})
 }
 
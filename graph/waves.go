@@ -0,0 +1,132 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package graph
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/compose-spec/compose-go/v2/utils"
+)
+
+// Waves returns project services grouped by waves which can be started together, in dependency order.
+// InReverseOrder option computes waves for services to be stopped, dependents first, and WithRootNodesAndDown
+// restricts waves to the selected services and their dependents. Services are sorted by name inside each wave
+func Waves(project *types.Project, options ...func(*Options)) ([][]string, error) {
+	t := newTraversal[types.ServiceConfig, any](nil)
+	for _, option := range options {
+		option(t.Options)
+	}
+	g, err := newGraph(project, t.implicitDependencies)
+	if err != nil {
+		return nil, err
+	}
+	return waves(g, t), nil
+}
+
+// waves groups graph vertices by the number of visited vertices they wait for, following traversal direction.
+// Vertices skipped by traversal are not part of any wave, and don't delay the ones waiting for them
+func waves[S, T any](g *graph[S], t *traversal[S, T]) [][]string {
+	levels := map[string]int{}
+	var level func(v *vertex[S]) int
+	level = func(v *vertex[S]) int {
+		if l, ok := levels[v.key]; ok {
+			return l
+		}
+		depends := v.children
+		if t.inverse {
+			depends = v.parents
+		}
+		l := 0
+		for _, d := range depends {
+			dl := level(d)
+			if !t.skip(d) {
+				dl++
+			}
+			l = max(l, dl)
+		}
+		levels[v.key] = l
+		return l
+	}
+
+	var res [][]string
+	for _, name := range utils.MapKeys(g.vertices) {
+		v := g.vertices[name]
+		if t.skip(v) {
+			continue
+		}
+		l := level(v)
+		for len(res) <= l {
+			res = append(res, nil)
+		}
+		res[l] = append(res[l], name)
+	}
+	return slices.DeleteFunc(res, func(wave []string) bool {
+		return len(wave) == 0
+	})
+}
+
+// BuildGraph creates the Graph of services with a build section, with edges for the build contexts
+// they get from another service image using `service:` additional contexts
+func BuildGraph(project *types.Project) (*Graph, error) {
+	g, err := newBuildGraph(project)
+	if err != nil {
+		return nil, err
+	}
+	return newView(project.Name, g, ""), nil
+}
+
+// BuildWaves returns services with a build section grouped by waves which can be built together, in
+// dependency order. Services are sorted by name inside each wave
+func BuildWaves(project *types.Project) ([][]string, error) {
+	g, err := newBuildGraph(project)
+	if err != nil {
+		return nil, err
+	}
+	return waves(g, newTraversal[types.ServiceConfig, any](nil)), nil
+}
+
+func newBuildGraph(project *types.Project) (*graph[types.ServiceConfig], error) {
+	g := &graph[types.ServiceConfig]{
+		vertices: map[string]*vertex[types.ServiceConfig]{},
+	}
+	for name, s := range project.Services {
+		if s.Build != nil {
+			g.addVertex(name, s)
+		}
+	}
+	for name, v := range g.vertices {
+		for _, context := range utils.MapKeys(v.service.Build.AdditionalContexts) {
+			dep, ok := strings.CutPrefix(v.service.Build.AdditionalContexts[context], types.ServicePrefix)
+			if !ok {
+				continue
+			}
+			s, err := project.GetService(dep)
+			if err != nil {
+				return nil, fmt.Errorf("service %q uses build context %q from unknown service %q", name, context, dep)
+			}
+			if s.Build == nil {
+				// service image is pulled, not built
+				continue
+			}
+			g.addEdge(name, dep, types.DependencyAdditionalContexts)
+		}
+	}
+	return g, g.checkCycle()
+}
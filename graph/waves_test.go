@@ -0,0 +1,98 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package graph
+
+import (
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func TestWaves(t *testing.T) {
+	project := exampleProject()
+	project.Services["test4"] = types.ServiceConfig{
+		Name:      "test4",
+		DependsOn: types.DependsOnConfig{"test3": {}},
+	}
+	project.Services["test0"] = types.ServiceConfig{Name: "test0"}
+
+	waves, err := Waves(project)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, waves, [][]string{{"test0", "test3"}, {"test2", "test4"}, {"test1"}})
+
+	waves, err = Waves(project, InReverseOrder)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, waves, [][]string{{"test0", "test1", "test4"}, {"test2"}, {"test3"}})
+
+	waves, err = Waves(project, WithRootNodesAndDown([]string{"test2"}))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, waves, [][]string{{"test2"}, {"test1"}})
+}
+
+func buildProject() *types.Project {
+	return &types.Project{
+		Services: types.Services{
+			"base": {Name: "base", Build: &types.BuildConfig{Context: "base"}},
+			"app": {
+				Name: "app",
+				Build: &types.BuildConfig{
+					Context:            "app",
+					AdditionalContexts: types.Mapping{"base": "service:base", "assets": "./assets"},
+				},
+			},
+			"tool": {
+				Name: "tool",
+				Build: &types.BuildConfig{
+					Context:            "tool",
+					AdditionalContexts: types.Mapping{"runtime": "service:runtime"},
+				},
+			},
+			"runtime": {Name: "runtime", Image: "alpine"},
+		},
+	}
+}
+
+func TestBuildWaves(t *testing.T) {
+	waves, err := BuildWaves(buildProject())
+	assert.NilError(t, err)
+	assert.DeepEqual(t, waves, [][]string{{"base", "tool"}, {"app"}})
+
+	g, err := BuildGraph(buildProject())
+	assert.NilError(t, err)
+	assert.DeepEqual(t, g.Edges(), []Edge{
+		{From: "app", To: "base", Required: true, Kinds: []types.DependencyKind{types.DependencyAdditionalContexts}},
+	})
+}
+
+func TestBuildWavesErrors(t *testing.T) {
+	project := buildProject()
+	project.Services["base"] = types.ServiceConfig{
+		Name: "base",
+		Build: &types.BuildConfig{
+			Context:            "base",
+			AdditionalContexts: types.Mapping{"app": "service:app"},
+		},
+	}
+	_, err := BuildWaves(project)
+	assert.Error(t, err, "dependency cycle detected: app -[build.additional_contexts]-> base -[build.additional_contexts]-> app")
+
+	project = buildProject()
+	delete(project.Services, "runtime")
+	_, err = BuildWaves(project)
+	assert.Error(t, err, `service "tool" uses build context "runtime" from unknown service "runtime"`)
+}
@@ -0,0 +1,328 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package impact computes which services are affected by a set of changed files
+package impact
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/buildcontext"
+	"github.com/compose-spec/compose-go/v2/tree"
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/compose-spec/compose-go/v2/utils"
+	"github.com/compose-spec/compose-go/v2/watch"
+	"github.com/moby/patternmatcher"
+)
+
+// Action is the operation required to apply a change to a service
+type Action string
+
+const (
+	// ActionRebuild requires the service image to be rebuilt
+	ActionRebuild Action = "rebuild"
+	// ActionRecreate requires the service container to be recreated as its configuration changed
+	ActionRecreate Action = "recreate"
+	// ActionRestart requires the service container to be restarted
+	ActionRestart Action = "restart"
+	// ActionSync requires files to be synchronized into the service container
+	ActionSync Action = "sync"
+	// ActionExec requires the develop.watch exec hook to run
+	ActionExec Action = "exec"
+	// ActionLive requires no action, as the change is visible in the service container through a bind mount
+	ActionLive Action = "live"
+)
+
+// Reason explains why a service is affected by changes
+type Reason struct {
+	Action Action
+	// Source is the attribute of the compose model matching the changed path, or declaring the dependency
+	Source tree.Path
+	// Path is the changed file, unset for reasons propagated from a dependency
+	Path string
+	// Dependency is the service the change was propagated from
+	Dependency string
+}
+
+func (r Reason) String() string {
+	if r.Dependency != "" {
+		return fmt.Sprintf("%s: %s as %q is affected", r.Source, r.Action, r.Dependency)
+	}
+	return fmt.Sprintf("%s: %s as %s changed", r.Source, r.Action, r.Path)
+}
+
+// Impact lists the reasons each affected service has to be updated
+type Impact map[string][]Reason
+
+// Services returns the names of affected services, sorted
+func (i Impact) Services() []string {
+	return utils.MapKeys(i)
+}
+
+// Actions returns the actions required for a service, sorted
+func (i Impact) Actions(service string) []Action {
+	var actions []Action
+	for _, r := range i[service] {
+		if !slices.Contains(actions, r.Action) {
+			actions = append(actions, r.Action)
+		}
+	}
+	slices.Sort(actions)
+	return actions
+}
+
+func (i Impact) add(service string, r Reason) bool {
+	if slices.Contains(i[service], r) {
+		return false
+	}
+	i[service] = append(i[service], r)
+	return true
+}
+
+func (i Impact) has(service string, actions ...Action) bool {
+	return slices.ContainsFunc(i[service], func(r Reason) bool {
+		return slices.Contains(actions, r.Action)
+	})
+}
+
+// Analyze computes the services affected by changed files. Relative paths are resolved from the project
+// working directory.
+//
+// Files are matched against build contexts (honoring .dockerignore), dockerfiles, local additional contexts,
+// bind mounts, env and label files, config and secret files, and develop.watch triggers. Rebuilds are propagated
+// to services using an affected service image as additional context, and services declaring a depends_on
+// dependency with restart: true get restarted when their dependency has to be rebuilt, recreated or restarted
+func Analyze(project *types.Project, changed []string) (Impact, error) {
+	files := make([]string, len(changed))
+	for i, f := range changed {
+		if !filepath.IsAbs(f) {
+			f = filepath.Join(project.WorkingDir, f)
+		}
+		files[i] = filepath.Clean(f)
+	}
+
+	impact := Impact{}
+	for _, name := range project.ServiceNames() {
+		s := project.Services[name]
+		if err := analyzeService(impact, project, s, files); err != nil {
+			return nil, err
+		}
+	}
+	propagate(impact, project)
+	for _, reasons := range impact {
+		slices.SortFunc(reasons, func(a, b Reason) int {
+			if c := strings.Compare(string(a.Source), string(b.Source)); c != 0 {
+				return c
+			}
+			if c := strings.Compare(a.Path, b.Path); c != 0 {
+				return c
+			}
+			return strings.Compare(string(a.Action), string(b.Action))
+		})
+	}
+	return impact, nil
+}
+
+func analyzeService(impact Impact, project *types.Project, s types.ServiceConfig, files []string) error {
+	service := tree.NewPath("services").Next(s.Name)
+	match := func(action Action, source tree.Path, fn func(file string) bool) {
+		for _, f := range files {
+			if fn(f) {
+				impact.add(s.Name, Reason{Action: action, Source: source, Path: f})
+			}
+		}
+	}
+
+	if s.Build != nil {
+		if err := analyzeBuild(impact, project, s, files); err != nil {
+			return err
+		}
+	}
+	for i, v := range s.Volumes {
+		if v.Type == types.VolumeTypeBind && v.Source != "" {
+			match(ActionLive, service.Next("volumes").Next(strconv.Itoa(i)), within(resolve(project, v.Source)))
+		}
+	}
+	for i, f := range s.EnvFiles {
+		match(ActionRecreate, service.Next("env_file").Next(strconv.Itoa(i)), equals(resolve(project, f.Path)))
+	}
+	for i, f := range s.LabelFiles {
+		match(ActionRecreate, service.Next("label_file").Next(strconv.Itoa(i)), equals(resolve(project, f)))
+	}
+	for _, c := range s.Configs {
+		if config, ok := project.Configs[c.Source]; ok && config.File != "" {
+			match(ActionRestart, tree.NewPath("configs").Next(c.Source), within(resolve(project, config.File)))
+		}
+	}
+	for _, c := range s.Secrets {
+		if secret, ok := project.Secrets[c.Source]; ok && secret.File != "" {
+			match(ActionRestart, tree.NewPath("secrets").Next(c.Source), within(resolve(project, secret.File)))
+		}
+	}
+	if s.Develop != nil {
+		matcher, err := watch.New(project, s)
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			for _, m := range matcher.Match(f) {
+				for _, action := range triggerActions(m.Action) {
+					impact.add(s.Name, Reason{Action: action, Source: service.Next("develop").Next("watch").Next(strconv.Itoa(m.Index)), Path: f})
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func analyzeBuild(impact Impact, project *types.Project, s types.ServiceConfig, files []string) error {
+	service := tree.NewPath("services").Next(s.Name)
+	build := s.Build
+	context, local := buildcontext.LocalDir(project.WorkingDir, build.Context)
+	dockerfile := ""
+	if local {
+		dockerfile = buildcontext.Dockerfile(context, build)
+	} else if build.DockerfileInline == "" && filepath.IsAbs(build.Dockerfile) {
+		dockerfile = build.Dockerfile
+	}
+	if dockerfile != "" {
+		for _, f := range files {
+			if f == dockerfile {
+				impact.add(s.Name, Reason{Action: ActionRebuild, Source: service.Next("build").Next("dockerfile"), Path: f})
+			}
+		}
+	}
+	if local {
+		matcher, err := contextMatcher(context, dockerfile)
+		if err != nil {
+			return fmt.Errorf("service %q: %w", s.Name, err)
+		}
+		for _, f := range files {
+			if f != dockerfile && within(context)(f) && !matcher(f) {
+				impact.add(s.Name, Reason{Action: ActionRebuild, Source: service.Next("build").Next("context"), Path: f})
+			}
+		}
+	}
+	for _, name := range utils.MapKeys(build.AdditionalContexts) {
+		dir, ok := buildcontext.LocalDir(project.WorkingDir, build.AdditionalContexts[name])
+		if !ok {
+			continue
+		}
+		matcher, err := contextMatcher(dir, "")
+		if err != nil {
+			return fmt.Errorf("service %q: %w", s.Name, err)
+		}
+		for _, f := range files {
+			if within(dir)(f) && !matcher(f) {
+				impact.add(s.Name, Reason{Action: ActionRebuild, Source: service.Next("build").Next("additional_contexts").Next(name), Path: f})
+			}
+		}
+	}
+	return nil
+}
+
+// propagate rebuilds to services using another service image as additional context, and restarts to dependents
+// declaring depends_on with restart: true, until no more service is affected
+func propagate(impact Impact, project *types.Project) {
+	for changed := true; changed; {
+		changed = false
+		for _, name := range project.ServiceNames() {
+			s := project.Services[name]
+			service := tree.NewPath("services").Next(name)
+			if s.Build != nil {
+				for _, context := range utils.MapKeys(s.Build.AdditionalContexts) {
+					dep, ok := strings.CutPrefix(s.Build.AdditionalContexts[context], types.ServicePrefix)
+					if ok && impact.has(dep, ActionRebuild) {
+						changed = impact.add(name, Reason{
+							Action:     ActionRebuild,
+							Source:     service.Next("build").Next("additional_contexts").Next(context),
+							Dependency: dep,
+						}) || changed
+					}
+				}
+			}
+			for _, dep := range utils.MapKeys(s.DependsOn) {
+				if s.DependsOn[dep].Restart && impact.has(dep, ActionRebuild, ActionRecreate, ActionRestart) {
+					changed = impact.add(name, Reason{
+						Action:     ActionRestart,
+						Source:     service.Next("depends_on").Next(dep),
+						Dependency: dep,
+					}) || changed
+				}
+			}
+		}
+	}
+}
+
+func triggerActions(action types.WatchAction) []Action {
+	switch action {
+	case types.WatchActionSync:
+		return []Action{ActionSync}
+	case types.WatchActionRebuild:
+		return []Action{ActionRebuild}
+	case types.WatchActionRestart:
+		return []Action{ActionRestart}
+	case types.WatchActionSyncRestart:
+		return []Action{ActionSync, ActionRestart}
+	case types.WatchActionSyncExec:
+		return []Action{ActionSync, ActionExec}
+	}
+	return nil
+}
+
+// contextMatcher returns a func reporting files excluded from a local build context by .dockerignore
+func contextMatcher(dir string, dockerfile string) (func(string) bool, error) {
+	m, err := buildcontext.IgnoreForDockerfile(dir, dockerfile)
+	if err != nil {
+		return nil, err
+	}
+	return func(file string) bool {
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return false
+		}
+		// ignore files are always sent to the builder
+		return rel != ".dockerignore" && file != dockerfile+".dockerignore" && matches(m, rel)
+	}, nil
+}
+
+// matches checks path or one of its parent directories matches patterns
+func matches(m *patternmatcher.PatternMatcher, path string) bool {
+	matched, err := m.MatchesOrParentMatches(path)
+	return err == nil && matched
+}
+
+func resolve(project *types.Project, path string) string {
+	return filepath.Clean(project.RelativePath(path))
+}
+
+func equals(path string) func(string) bool {
+	return func(file string) bool {
+		return file == path
+	}
+}
+
+// within returns a func reporting files which are path itself or inside path directory
+func within(path string) func(string) bool {
+	return func(file string) bool {
+		rel, err := filepath.Rel(path, file)
+		return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+	}
+}
@@ -0,0 +1,153 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package impact
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/tree"
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func TestAnalyze(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.MkdirAll(filepath.Join(dir, "base"), 0o755))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "base", ".dockerignore"), []byte("*.md\n"), 0o600))
+
+	project := &types.Project{
+		WorkingDir: dir,
+		Services: types.Services{
+			"base": {
+				Name:  "base",
+				Build: &types.BuildConfig{Context: filepath.Join(dir, "base")},
+			},
+			"app": {
+				Name: "app",
+				Build: &types.BuildConfig{
+					Context:            filepath.Join(dir, "app"),
+					Dockerfile:         "../dockerfiles/app.Dockerfile",
+					AdditionalContexts: types.Mapping{"base": "service:base"},
+				},
+			},
+			"web": {
+				Name: "web",
+				DependsOn: types.DependsOnConfig{
+					"app": {Condition: types.ServiceConditionStarted, Required: true, Restart: true},
+				},
+				Volumes: []types.ServiceVolumeConfig{
+					{Type: types.VolumeTypeBind, Source: filepath.Join(dir, "html"), Target: "/usr/share/html"},
+					{Type: types.VolumeTypeVolume, Source: "data", Target: "/data"},
+				},
+				EnvFiles: []types.EnvFile{{Path: filepath.Join(dir, ".env")}},
+				Configs:  []types.ServiceConfigObjConfig{{Source: "cfg"}},
+				Develop: &types.DevelopConfig{
+					Watch: []types.Trigger{
+						{Path: filepath.Join(dir, "web"), Action: types.WatchActionSyncRestart, Target: "/app", Ignore: []string{"*.tmp"}},
+					},
+				},
+			},
+			"worker": {
+				Name: "worker",
+				DependsOn: types.DependsOnConfig{
+					"app": {Condition: types.ServiceConditionStarted, Required: true},
+				},
+			},
+		},
+		Configs: types.Configs{
+			"cfg": {File: filepath.Join(dir, "cfg.toml")},
+		},
+	}
+
+	impact, err := Analyze(project, []string{
+		"base/src/main.go",
+		"base/README.md",
+		"base/.dockerignore",
+		"html/index.html",
+		".env",
+		"cfg.toml",
+		"web/app.js",
+		"web/cache.tmp",
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, impact.Services(), []string{"app", "base", "web"})
+
+	assert.DeepEqual(t, impact["base"], []Reason{
+		{Action: ActionRebuild, Source: tree.NewPath("services", "base", "build", "context"), Path: filepath.Join(dir, "base", ".dockerignore")},
+		{Action: ActionRebuild, Source: tree.NewPath("services", "base", "build", "context"), Path: filepath.Join(dir, "base", "src", "main.go")},
+	})
+	assert.DeepEqual(t, impact["app"], []Reason{
+		{Action: ActionRebuild, Source: tree.NewPath("services", "app", "build", "additional_contexts", "base"), Dependency: "base"},
+	})
+	assert.DeepEqual(t, impact.Actions("web"), []Action{ActionLive, ActionRecreate, ActionRestart, ActionSync})
+	assert.DeepEqual(t, impact["web"], []Reason{
+		{Action: ActionRestart, Source: tree.NewPath("configs", "cfg"), Path: filepath.Join(dir, "cfg.toml")},
+		{Action: ActionRestart, Source: tree.NewPath("services", "web", "depends_on", "app"), Dependency: "app"},
+		{Action: ActionRestart, Source: tree.NewPath("services", "web", "develop", "watch", "0"), Path: filepath.Join(dir, "web", "app.js")},
+		{Action: ActionSync, Source: tree.NewPath("services", "web", "develop", "watch", "0"), Path: filepath.Join(dir, "web", "app.js")},
+		{Action: ActionRecreate, Source: tree.NewPath("services", "web", "env_file", "0"), Path: filepath.Join(dir, ".env")},
+		{Action: ActionLive, Source: tree.NewPath("services", "web", "volumes", "0"), Path: filepath.Join(dir, "html", "index.html")},
+	})
+	assert.Equal(t, impact["web"][1].String(), `services.web.depends_on.app: restart as "app" is affected`)
+}
+
+func TestAnalyzeBindMount(t *testing.T) {
+	dir := t.TempDir()
+	project := &types.Project{
+		WorkingDir: dir,
+		Services: types.Services{
+			"app": {
+				Name: "app",
+				Volumes: []types.ServiceVolumeConfig{
+					{Type: types.VolumeTypeBind, Source: filepath.Join(dir, "src"), Target: "/src"},
+				},
+			},
+			"proxy": {
+				Name:      "proxy",
+				DependsOn: types.DependsOnConfig{"app": {Condition: types.ServiceConditionStarted, Restart: true, Required: true}},
+			},
+		},
+	}
+	impact, err := Analyze(project, []string{"src/main.go"})
+	assert.NilError(t, err)
+	// bind mounted files are live in container, so dependents don't get restarted
+	assert.DeepEqual(t, impact.Services(), []string{"app"})
+	assert.DeepEqual(t, impact.Actions("app"), []Action{ActionLive})
+}
+
+func TestAnalyzeDockerfile(t *testing.T) {
+	dir := t.TempDir()
+	project := &types.Project{
+		WorkingDir: dir,
+		Services: types.Services{
+			"app": {
+				Name: "app",
+				Build: &types.BuildConfig{
+					Context:    filepath.Join(dir, "app"),
+					Dockerfile: "../dockerfiles/app.Dockerfile",
+				},
+			},
+		},
+	}
+	impact, err := Analyze(project, []string{"dockerfiles/app.Dockerfile", "dockerfiles/other.Dockerfile"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, impact["app"], []Reason{
+		{Action: ActionRebuild, Source: tree.NewPath("services", "app", "build", "dockerfile"), Path: filepath.Join(dir, "dockerfiles", "app.Dockerfile")},
+	})
+}
@@ -20,6 +20,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"slices"
 
 	"github.com/compose-spec/compose-go/v2/template"
 	"github.com/compose-spec/compose-go/v2/tree"
@@ -31,6 +32,8 @@ type Options struct {
 	LookupValue LookupValue
 	// TypeCastMapping maps key paths to functions to cast to a type
 	TypeCastMapping map[tree.Path]Cast
+	// KeysMapping lists paths to mappings whose keys should be interpolated, in addition to values
+	KeysMapping []tree.Path
 	// Substitution function to use
 	Substitute func(string, template.Mapping) (string, error)
 }
@@ -87,13 +90,32 @@ func recursiveInterpolate(value interface{}, path tree.Path, opts Options) (inte
 		return casted, nil
 
 	case map[string]interface{}:
+		interpolateKeys := opts.interpolateKeysForPath(path)
 		out := map[string]interface{}{}
-		for key, elem := range value {
-			interpolatedElem, err := recursiveInterpolate(elem, path.Next(key), opts)
+		origins := map[string]string{}
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			newKey := key
+			if interpolateKeys {
+				var err error
+				newKey, err = opts.Substitute(key, template.Mapping(opts.LookupValue))
+				if err != nil {
+					return nil, newPathError(path.Next(key), err)
+				}
+				if origin, ok := origins[newKey]; ok {
+					return nil, newPathError(path, fmt.Errorf("keys %q and %q both resolve to %q", origin, key, newKey))
+				}
+				origins[newKey] = key
+			}
+			interpolatedElem, err := recursiveInterpolate(value[key], path.Next(newKey), opts)
 			if err != nil {
 				return nil, err
 			}
-			out[key] = interpolatedElem
+			out[newKey] = interpolatedElem
 		}
 		return out, nil
 
@@ -127,6 +149,15 @@ func newPathError(path tree.Path, err error) error {
 	}
 }
 
+func (o Options) interpolateKeysForPath(path tree.Path) bool {
+	for _, pattern := range o.KeysMapping {
+		if path.Matches(pattern) {
+			return true
+		}
+	}
+	return false
+}
+
 func (o Options) getCasterForPath(path tree.Path) (Cast, bool) {
 	for pattern, caster := range o.TypeCastMapping {
 		if path.Matches(pattern) {
@@ -267,3 +267,53 @@ func TestPathMatches(t *testing.T) {
 		assert.Check(t, is.Equal(testcase.expected, testcase.path.Matches(testcase.pattern)))
 	}
 }
+
+func TestInterpolateKeys(t *testing.T) {
+	config := map[string]interface{}{
+		"foo": map[string]interface{}{
+			"labels": map[string]interface{}{
+				"${FOO}_user": "$USER",
+			},
+			"${FOO}": map[string]interface{}{
+				"${USER}": "$count",
+			},
+		},
+	}
+	result, err := Interpolate(config, Options{
+		LookupValue: defaultMapping,
+		TypeCastMapping: map[tree.Path]Cast{
+			tree.NewPath(tree.PathMatchAll, "labels", "bar_user"): func(value string) (interface{}, error) {
+				return "cast:" + value, nil
+			},
+		},
+		KeysMapping: []tree.Path{
+			tree.NewPath(tree.PathMatchAll, "labels"),
+		},
+	})
+	assert.NilError(t, err)
+	expected := map[string]interface{}{
+		"foo": map[string]interface{}{
+			"labels": map[string]interface{}{
+				"bar_user": "cast:jenny",
+			},
+			"${FOO}": map[string]interface{}{
+				"${USER}": "5",
+			},
+		},
+	}
+	assert.Check(t, is.DeepEqual(expected, result))
+}
+
+func TestInterpolateKeysCollision(t *testing.T) {
+	config := map[string]interface{}{
+		"labels": map[string]interface{}{
+			"${FOO}": "one",
+			"bar":    "two",
+		},
+	}
+	_, err := Interpolate(config, Options{
+		LookupValue: defaultMapping,
+		KeysMapping: []tree.Path{tree.NewPath("labels")},
+	})
+	assert.Error(t, err, `error while interpolating labels: keys "${FOO}" and "bar" both resolve to "bar"`)
+}
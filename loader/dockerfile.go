@@ -0,0 +1,52 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package loader
+
+import (
+	"fmt"
+
+	"github.com/compose-spec/compose-go/v2/dockerfile"
+	"github.com/compose-spec/compose-go/v2/errdefs"
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/sirupsen/logrus"
+)
+
+// checkDockerfiles validates services build sections against the Dockerfile. Issues which would make the
+// build fail are reported as errors, others are logged as warnings
+func checkDockerfiles(project *types.Project) error {
+	for _, name := range project.ServiceNames() {
+		s := project.Services[name]
+		if s.Build == nil {
+			continue
+		}
+		issues, err := dockerfile.Check(project, s)
+		if err != nil {
+			return err
+		}
+		for _, issue := range issues {
+			message := issue.Message
+			if issue.Line > 0 {
+				message = fmt.Sprintf("Dockerfile line %d: %s", issue.Line, message)
+			}
+			if issue.Severity == dockerfile.SeverityError {
+				return fmt.Errorf("service %q: %s: %w", name, message, errdefs.ErrInvalid)
+			}
+			logrus.Warnf("service %q: %s", name, message)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package loader
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/errdefs"
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func TestLoadWithDockerfileCheck(t *testing.T) {
+	tmpdir := t.TempDir()
+	createFile(t, tmpdir, "FROM alpine AS base\nRUN --mount=type=secret,id=token,required cat /run/secrets/token\n", "Dockerfile")
+	path := createFile(t, tmpdir, `
+name: dockerfile
+services:
+  app:
+    build:
+      context: .
+      target: prod
+`, "compose.yaml")
+	details := types.ConfigDetails{
+		WorkingDir:  tmpdir,
+		ConfigFiles: []types.ConfigFile{{Filename: path}},
+		Environment: map[string]string{},
+	}
+
+	_, err := LoadWithContext(context.TODO(), details)
+	assert.NilError(t, err)
+
+	_, err = LoadWithContext(context.TODO(), details, WithDockerfileCheck)
+	assert.ErrorIs(t, err, errdefs.ErrInvalid)
+	assert.ErrorContains(t, err, `service "app": target stage "prod" not found in Dockerfile`)
+
+	createFile(t, tmpdir, `
+name: dockerfile
+services:
+  app:
+    build:
+      context: .
+      target: base
+`, "compose.yaml")
+	_, err = LoadWithContext(context.TODO(), details, WithDockerfileCheck)
+	assert.ErrorContains(t, err, `service "app": Dockerfile line 2: secret "token" is mounted but not declared by build.secrets`)
+
+	_, err = LoadWithContext(context.TODO(), details, WithDockerfileCheck, func(options *Options) {
+		options.SkipConsistencyCheck = true
+	})
+	assert.NilError(t, err)
+}
+
+func TestLoadWithDockerfileCheckSelectedServices(t *testing.T) {
+	tmpdir := t.TempDir()
+	createFile(t, tmpdir, "FROM alpine\n", "Dockerfile")
+	path := createFile(t, tmpdir, `
+name: dockerfile
+services:
+  app:
+    build: .
+  broken:
+    build:
+      context: .
+      dockerfile: missing.Dockerfile
+`, "compose.yaml")
+	details := types.ConfigDetails{
+		WorkingDir:  tmpdir,
+		ConfigFiles: []types.ConfigFile{{Filename: path}},
+		Environment: map[string]string{},
+	}
+
+	_, err := LoadWithContext(context.TODO(), details, WithDockerfileCheck)
+	assert.ErrorIs(t, err, errdefs.ErrInvalid)
+	assert.ErrorContains(t, err, fmt.Sprintf(`service "broken": Dockerfile %s not found`, filepath.Join(tmpdir, "missing.Dockerfile")))
+
+	// services which are not selected are not checked
+	_, err = LoadWithContext(context.TODO(), details, WithDockerfileCheck, WithSelectedServices([]string{"app"}))
+	assert.NilError(t, err)
+}
@@ -150,6 +150,7 @@ func ApplyInclude(ctx context.Context, workingDir string, environment types.Mapp
 			Substitute:      options.Interpolate.Substitute,
 			LookupValue:     config.LookupEnv,
 			TypeCastMapping: options.Interpolate.TypeCastMapping,
+			KeysMapping:     options.Interpolate.KeysMapping,
 		}
 		imported, err := loadYamlModel(ctx, config, loadOptions, &cycleTracker{}, included)
 		if err != nil {
@@ -71,6 +71,23 @@ var interpolateTypeCastMapping = map[tree.Path]interp.Cast{
 	iPath("configs", tree.PathMatchAll, "external"):                toBoolean,
 }
 
+// interpolateKeysMapping lists mappings whose keys are interpolated when WithKeysInterpolation is set
+var interpolateKeysMapping = []tree.Path{
+	tree.PathMatchExtension,
+	servicePath(tree.PathMatchExtension),
+	servicePath("labels"),
+	servicePath("annotations"),
+	servicePath("environment"),
+	servicePath("sysctls"),
+	servicePath("extra_hosts"),
+	servicePath("build", "labels"),
+	servicePath("deploy", "labels"),
+	iPath("networks", tree.PathMatchAll, "labels"),
+	iPath("volumes", tree.PathMatchAll, "labels"),
+	iPath("secrets", tree.PathMatchAll, "labels"),
+	iPath("configs", tree.PathMatchAll, "labels"),
+}
+
 func iPath(parts ...string) tree.Path {
 	return tree.NewPath(parts...)
 }
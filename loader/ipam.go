@@ -0,0 +1,171 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package loader
+
+import (
+	"fmt"
+	"net/netip"
+	"slices"
+
+	"github.com/compose-spec/compose-go/v2/errdefs"
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/compose-spec/compose-go/v2/utils"
+	"github.com/sirupsen/logrus"
+)
+
+type subnet struct {
+	network string
+	prefix  netip.Prefix
+	bridge  bool
+}
+
+type networkAddress struct {
+	network string
+	addr    netip.Addr
+}
+
+// checkIPAM validates networks IPAM configuration and services static addresses are consistent:
+// bridge networks subnets don't overlap, addresses belong to their subnet and are assigned at most once
+func checkIPAM(project *types.Project) error { //nolint:gocyclo
+	var subnets []subnet
+	// reserved tracks addresses already assigned on a network, and what they're assigned to
+	reserved := map[networkAddress]string{}
+	for _, name := range project.NetworkNames() {
+		network := project.Networks[name]
+		if network.External {
+			continue
+		}
+		for i, pool := range network.Ipam.Config {
+			if pool == nil {
+				continue
+			}
+			loc := fmt.Sprintf("networks.%s.ipam.config[%d]", name, i)
+			if pool.Subnet == "" {
+				if pool.Gateway != "" || pool.IPRange != "" || len(pool.AuxiliaryAddresses) > 0 {
+					return fmt.Errorf("%s: gateway, ip_range and aux_addresses require a subnet: %w", loc, errdefs.ErrInvalid)
+				}
+				continue
+			}
+			prefix, err := netip.ParsePrefix(pool.Subnet)
+			if err != nil {
+				return fmt.Errorf("%s: invalid subnet %q: %w", loc, pool.Subnet, errdefs.ErrInvalid)
+			}
+			prefix = prefix.Masked()
+			// other drivers, like macvlan or ipvlan, legitimately share a subnet with the host or another network
+			if isBridge(network) {
+				for _, other := range subnets {
+					if other.bridge && other.prefix.Overlaps(prefix) {
+						return fmt.Errorf("%s: subnet %s overlaps with subnet %s of network %s: %w", loc, prefix, other.prefix, other.network, errdefs.ErrInvalid)
+					}
+				}
+			}
+			subnets = append(subnets, subnet{network: name, prefix: prefix, bridge: isBridge(network)})
+
+			if pool.Gateway != "" {
+				gateway, err := netip.ParseAddr(pool.Gateway)
+				if err != nil {
+					return fmt.Errorf("%s: invalid gateway %q: %w", loc, pool.Gateway, errdefs.ErrInvalid)
+				}
+				if !prefix.Contains(gateway) {
+					return fmt.Errorf("%s: gateway %s is not in subnet %s: %w", loc, gateway, prefix, errdefs.ErrInvalid)
+				}
+				reserved[networkAddress{network: name, addr: gateway}] = fmt.Sprintf("the gateway of network %s", name)
+			}
+			if pool.IPRange != "" {
+				ipRange, err := netip.ParsePrefix(pool.IPRange)
+				if err != nil {
+					return fmt.Errorf("%s: invalid ip_range %q: %w", loc, pool.IPRange, errdefs.ErrInvalid)
+				}
+				if ipRange.Bits() < prefix.Bits() || !prefix.Contains(ipRange.Masked().Addr()) {
+					return fmt.Errorf("%s: ip_range %s is not in subnet %s: %w", loc, ipRange, prefix, errdefs.ErrInvalid)
+				}
+			}
+			for _, host := range utils.MapKeys(pool.AuxiliaryAddresses) {
+				value := pool.AuxiliaryAddresses[host]
+				addr, err := netip.ParseAddr(value)
+				if err != nil {
+					return fmt.Errorf("%s: invalid aux_addresses %s %q: %w", loc, host, value, errdefs.ErrInvalid)
+				}
+				if !prefix.Contains(addr) {
+					return fmt.Errorf("%s: aux_addresses %s %s is not in subnet %s: %w", loc, host, addr, prefix, errdefs.ErrInvalid)
+				}
+				key := networkAddress{network: name, addr: addr}
+				if other, ok := reserved[key]; ok {
+					return fmt.Errorf("%s: aux_addresses %s %s is already assigned to %s: %w", loc, host, addr, other, errdefs.ErrInvalid)
+				}
+				reserved[key] = fmt.Sprintf("aux address %s of network %s", host, name)
+			}
+		}
+	}
+
+	for _, name := range project.ServiceNames() {
+		s := project.Services[name]
+		for _, network := range utils.MapKeys(s.Networks) {
+			config := s.Networks[network]
+			if config == nil {
+				continue
+			}
+			for _, static := range []struct {
+				attribute string
+				value     string
+				ipv6      bool
+			}{
+				{attribute: "ipv4_address", value: config.Ipv4Address},
+				{attribute: "ipv6_address", value: config.Ipv6Address, ipv6: true},
+			} {
+				if static.value == "" {
+					continue
+				}
+				loc := fmt.Sprintf("services.%s.networks.%s.%s", name, network, static.attribute)
+				addr, err := netip.ParseAddr(static.value)
+				if err != nil || addr.Is6() != static.ipv6 {
+					return fmt.Errorf("%s: invalid address %q: %w", loc, static.value, errdefs.ErrInvalid)
+				}
+				if !inNetworkSubnets(subnets, network, addr) {
+					return fmt.Errorf("%s: %s is not in a subnet of network %s: %w", loc, addr, network, errdefs.ErrInvalid)
+				}
+				if scale := s.GetScale(); scale > 1 {
+					logrus.Warnf("%s: static address %s can only be assigned to one of %d replicas", loc, addr, scale)
+				}
+				key := networkAddress{network: network, addr: addr}
+				if other, ok := reserved[key]; ok {
+					return fmt.Errorf("%s: %s is already assigned to %s: %w", loc, addr, other, errdefs.ErrInvalid)
+				}
+				reserved[key] = fmt.Sprintf("service %s", name)
+			}
+		}
+	}
+	return nil
+}
+
+// inNetworkSubnets checks addr belongs to a subnet of network, if any is declared for the address family
+func inNetworkSubnets(subnets []subnet, network string, addr netip.Addr) bool {
+	declared := slices.ContainsFunc(subnets, func(s subnet) bool {
+		return s.network == network && s.prefix.Addr().Is6() == addr.Is6()
+	})
+	if !declared {
+		return true
+	}
+	return slices.ContainsFunc(subnets, func(s subnet) bool {
+		return s.network == network && s.prefix.Contains(addr)
+	})
+}
+
+// isBridge checks network uses the default bridge driver
+func isBridge(network types.NetworkConfig) bool {
+	return network.Driver == "" || network.Driver == "bridge"
+}
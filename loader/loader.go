@@ -30,7 +30,10 @@ import (
 	"strconv"
 	"strings"
 
+	"filippo.io/age"
 	"github.com/compose-spec/compose-go/v2/consts"
+	"github.com/compose-spec/compose-go/v2/dotenv"
+	"github.com/compose-spec/compose-go/v2/drivers"
 	"github.com/compose-spec/compose-go/v2/errdefs"
 	interp "github.com/compose-spec/compose-go/v2/interpolation"
 	"github.com/compose-spec/compose-go/v2/override"
@@ -60,6 +63,14 @@ type Options struct {
 	ConvertWindowsPaths bool
 	// Skip consistency check
 	SkipConsistencyCheck bool
+	// CheckDockerfiles extends consistency check to validate build sections against the Dockerfile
+	CheckDockerfiles bool
+	// CheckPortConflicts extends consistency check to detect host ports published by multiple services
+	CheckPortConflicts bool
+	// DetectEnvFileFormat sets the format of env_file entries without an explicit one from the file extension
+	DetectEnvFileFormat bool
+	// CheckDriverOptions extends consistency check to validate logging, network and volume driver options
+	CheckDriverOptions bool
 	// Skip extends
 	SkipExtends bool
 	// SkipInclude will ignore `include` and only load model from file(s) set by ConfigDetails
@@ -72,6 +83,8 @@ type Options struct {
 	Interpolate *interp.Options
 	// Discard 'env_file' entries after resolving to 'environment' section
 	discardEnvFiles bool
+	// ageIdentities decrypt env_file using the age format, overriding COMPOSE_AGE_KEY and COMPOSE_AGE_KEY_FILE
+	ageIdentities []age.Identity
 	// Set project projectName
 	projectName string
 	// Indicates when the projectName was imperatively set or guessed from path
@@ -92,6 +105,9 @@ type Options struct {
 	KnownExtensions map[string]any
 	// Metada for telemetry
 	Listeners []Listener
+	// TargetVersion is the docker compose version the model must be compatible with. When set,
+	// attributes introduced by a later version are reported as errdefs.ErrUnsupported
+	TargetVersion string
 	// MaxNodeVisits caps total YAML node visits during reset/override resolution.
 	// Zero means use the default. Useful for very large compose files that exceed the default cap.
 	MaxNodeVisits int
@@ -188,10 +204,15 @@ func (o *Options) clone() *Options {
 		ResolvePaths:               o.ResolvePaths,
 		ConvertWindowsPaths:        o.ConvertWindowsPaths,
 		SkipConsistencyCheck:       o.SkipConsistencyCheck,
+		CheckDockerfiles:           o.CheckDockerfiles,
+		CheckPortConflicts:         o.CheckPortConflicts,
+		CheckDriverOptions:         o.CheckDriverOptions,
+		DetectEnvFileFormat:        o.DetectEnvFileFormat,
 		SkipExtends:                o.SkipExtends,
 		SkipInclude:                o.SkipInclude,
 		Interpolate:                o.Interpolate,
 		discardEnvFiles:            o.discardEnvFiles,
+		ageIdentities:              o.ageIdentities,
 		projectName:                o.projectName,
 		projectNameImperativelySet: o.projectNameImperativelySet,
 		Profiles:                   o.Profiles,
@@ -200,6 +221,7 @@ func (o *Options) clone() *Options {
 		ResourceLoaders:            o.ResourceLoaders,
 		KnownExtensions:            o.KnownExtensions,
 		Listeners:                  o.Listeners,
+		TargetVersion:              o.TargetVersion,
 	}
 }
 
@@ -258,11 +280,64 @@ func WithDiscardEnvFiles(opts *Options) {
 	opts.discardEnvFiles = true
 }
 
+// WithAgeIdentities sets the Options to decrypt env_file using the age format with identities, rather than
+// those set by COMPOSE_AGE_KEY or COMPOSE_AGE_KEY_FILE
+func WithAgeIdentities(identities ...age.Identity) func(*Options) {
+	return func(opts *Options) {
+		opts.ageIdentities = identities
+	}
+}
+
 // WithSkipValidation sets the Options to skip validation when loading sections
 func WithSkipValidation(opts *Options) {
 	opts.SkipValidation = true
 }
 
+// WithKeysInterpolation sets the Options to also interpolate mapping keys for labels, annotations,
+// environment, sysctls, extra_hosts and x-* extensions. When Options don't set Interpolate, default
+// interpolation options are used, looking up variables from the OS environment
+func WithKeysInterpolation(opts *Options) {
+	interpolate := interp.Options{
+		Substitute:      template.Substitute,
+		TypeCastMapping: interpolateTypeCastMapping,
+	}
+	if opts.Interpolate != nil {
+		interpolate = *opts.Interpolate
+	}
+	interpolate.KeysMapping = interpolateKeysMapping
+	opts.Interpolate = &interpolate
+}
+
+// WithDockerfileCheck sets the Options to validate build sections against the Dockerfile
+func WithDockerfileCheck(opts *Options) {
+	opts.CheckDockerfiles = true
+}
+
+// WithPortConflictsCheck sets the Options to detect host ports published by multiple services, see
+// types.Project.CheckPortConflicts
+func WithPortConflictsCheck(opts *Options) {
+	opts.CheckPortConflicts = true
+}
+
+// WithDriverOptionsCheck sets the Options to validate driver options against the schemas registered
+// by package drivers
+func WithDriverOptionsCheck(opts *Options) {
+	opts.CheckDriverOptions = true
+}
+
+// WithEnvFileFormatDetection sets the Options to detect env_file format from the file extension when not
+// set explicitly, see dotenv.DetectFormat
+func WithEnvFileFormatDetection(opts *Options) {
+	opts.DetectEnvFileFormat = true
+}
+
+// WithTargetVersion sets the docker compose version the model must be compatible with
+func WithTargetVersion(version string) func(*Options) {
+	return func(opts *Options) {
+		opts.TargetVersion = version
+	}
+}
+
 // WithProfiles sets profiles to be activated
 func WithProfiles(profiles []string) func(*Options) {
 	return func(opts *Options) {
@@ -475,6 +550,12 @@ func loadYamlFile(ctx context.Context,
 
 		fixEmptyNotNull(cfg)
 
+		if opts.TargetVersion != "" {
+			if err := validation.CheckTargetVersion(cfg, opts.TargetVersion); err != nil {
+				return fmt.Errorf("validating %s: %w", file.Filename, err)
+			}
+		}
+
 		// Process includes first so that extended services have all merged attributes
 		if !opts.SkipInclude {
 			included = append(included, file.Filename)
@@ -627,6 +708,16 @@ func ModelToProject(dict map[string]interface{}, opts *Options, configDetails ty
 		if err != nil {
 			return nil, err
 		}
+		if opts.CheckPortConflicts {
+			if err := project.CheckPortConflicts(); err != nil {
+				return nil, err
+			}
+		}
+		if opts.CheckDriverOptions {
+			if err := drivers.ValidateProject(project); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	if len(opts.SelectedServices) > 0 {
@@ -643,12 +734,26 @@ func ModelToProject(dict map[string]interface{}, opts *Options, configDetails ty
 		}
 	}
 
+	if !opts.SkipConsistencyCheck && opts.CheckDockerfiles {
+		// only check Dockerfiles for selected services
+		if err := checkDockerfiles(project); err != nil {
+			return nil, err
+		}
+	}
+
 	if opts.PruneUnnecessaryResources {
 		project = project.WithoutUnnecessaryResources()
 	}
 
 	if !opts.SkipResolveEnvironment {
-		project, err = project.WithServicesEnvironmentResolved(opts.discardEnvFiles)
+		var envFileOptions []types.EnvFileOption
+		if len(opts.ageIdentities) > 0 {
+			envFileOptions = append(envFileOptions, types.WithEnvFileParser(dotenv.Age, dotenv.AgeParser(opts.ageIdentities...)))
+		}
+		if opts.DetectEnvFileFormat {
+			envFileOptions = append(envFileOptions, types.WithEnvFileFormatDetection)
+		}
+		project, err = project.WithServicesEnvironmentResolved(opts.discardEnvFiles, envFileOptions...)
 		if err != nil {
 			return nil, err
 		}
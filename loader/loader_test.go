@@ -31,6 +31,7 @@ import (
 	"gotest.tools/v3/assert"
 	is "gotest.tools/v3/assert/cmp"
 
+	"github.com/compose-spec/compose-go/v2/errdefs"
 	"github.com/compose-spec/compose-go/v2/types"
 )
 
@@ -697,6 +698,83 @@ volumes:
 	assert.Check(t, is.Equal(home, config.Volumes["test"].Driver))
 }
 
+func TestLoadWithKeysInterpolation(t *testing.T) {
+	yaml := `
+name: load-with-keys-interpolation
+x-${PREFIX}: ignored
+services:
+  test:
+    image: busybox
+    labels:
+      ${PREFIX}.team: backend
+    environment:
+      ${PREFIX}_MODE: dev
+    ulimits:
+      ${PREFIX}: 1
+    x-custom:
+      ${PREFIX}: value
+`
+	env := map[string]string{"PREFIX": "acme"}
+	config, err := LoadWithContext(context.TODO(), buildConfigDetails(yaml, env), func(options *Options) {
+		options.SkipConsistencyCheck = true
+		options.SkipNormalization = true
+	}, WithKeysInterpolation)
+	assert.NilError(t, err)
+	service := config.Services["test"]
+	assert.Check(t, is.DeepEqual(types.Labels{"acme.team": "backend"}, service.Labels))
+	assert.Check(t, is.DeepEqual(types.NewMappingWithEquals([]string{"acme_MODE=dev"}), service.Environment))
+	assert.Check(t, is.Contains(service.Ulimits, "${PREFIX}"))
+	assert.Check(t, is.DeepEqual(map[string]any{"acme": "value"}, service.Extensions["x-custom"]))
+	assert.Check(t, is.Contains(config.Extensions, "x-${PREFIX}"))
+
+	_, err = LoadWithContext(context.TODO(), buildConfigDetails(`
+name: load-with-keys-interpolation
+services:
+  test:
+    image: busybox
+    labels:
+      ${PREFIX}.team: backend
+      acme.team: frontend
+`, env), WithKeysInterpolation)
+	assert.ErrorContains(t, err, `keys "${PREFIX}.team" and "acme.team" both resolve to "acme.team"`)
+}
+
+func TestLoadWithKeysInterpolationDefaultOptions(t *testing.T) {
+	t.Setenv("PREFIX", "acme")
+	config, err := LoadWithContext(context.TODO(), buildConfigDetails(`
+name: load-with-keys-interpolation
+services:
+  test:
+    image: busybox
+    labels:
+      ${PREFIX}.team: backend
+`, nil), func(options *Options) {
+		options.Interpolate = nil
+	}, WithKeysInterpolation)
+	assert.NilError(t, err)
+	assert.Check(t, is.DeepEqual(types.Labels{"acme.team": "backend"}, config.Services["test"].Labels))
+}
+
+func TestLoadWithTargetVersion(t *testing.T) {
+	details := buildConfigDetails(`
+name: load-with-target-version
+services:
+  test:
+    image: busybox
+    env_file:
+      - path: test.env
+        required: false
+    provider:
+      type: model
+`, nil)
+	_, err := LoadWithContext(context.TODO(), details, WithTargetVersion("2.30.0"))
+	assert.Check(t, errdefs.IsUnsupportedError(err))
+	assert.ErrorContains(t, err, "services.test.provider requires docker compose 2.36.0, target is 2.30.0")
+
+	_, err = LoadWithContext(context.TODO(), details, WithTargetVersion("2.36.0"))
+	assert.NilError(t, err)
+}
+
 func TestLoadWithInterpolationCastFull(t *testing.T) {
 	dict := `
 name: load-with-interpolation-cast-full
@@ -3147,3 +3225,76 @@ volumes:
 	assert.Assert(t, !hasOrphanNet, "unreferenced network should be pruned")
 	assert.Assert(t, !hasOrphanVol, "unreferenced volume should be pruned")
 }
+
+func TestLoadWithPortConflictsCheck(t *testing.T) {
+	yaml := `
+name: ports
+services:
+  web:
+    image: nginx
+    ports:
+      - "8080:80"
+  api:
+    image: nginx
+    ports:
+      - "8080:8080"
+`
+	_, err := LoadWithContext(context.Background(), buildConfigDetails(yaml, nil))
+	assert.NilError(t, err)
+
+	_, err = LoadWithContext(context.Background(), buildConfigDetails(yaml, nil), WithPortConflictsCheck)
+	assert.ErrorIs(t, err, errdefs.ErrInvalid)
+	assert.ErrorContains(t, err, `services "api" and "web" both publish host port`)
+
+	_, err = LoadWithContext(context.Background(), buildConfigDetails(yaml, nil), WithPortConflictsCheck, func(options *Options) {
+		options.SkipConsistencyCheck = true
+	})
+	assert.NilError(t, err)
+}
+
+func TestLoadWithDriverOptionsCheck(t *testing.T) {
+	yaml := `
+name: drivers
+services:
+  web:
+    image: nginx
+    logging:
+      options:
+        max-size: 10m
+        max_file: "3"
+  api:
+    image: nginx
+    logging:
+      driver: local
+      options:
+        max-size: ${LOG_MAX_SIZE}
+`
+	_, err := LoadWithContext(context.Background(), buildConfigDetails(yaml, nil))
+	assert.NilError(t, err)
+
+	_, err = LoadWithContext(context.Background(), buildConfigDetails(yaml, nil), WithDriverOptionsCheck)
+	assert.Error(t, err, `services.web.logging.options: json-file logging driver: unknown option "max_file", did you mean "max-file"?: invalid compose project`)
+
+	_, err = LoadWithContext(context.Background(), buildConfigDetails(yaml, nil), WithDriverOptionsCheck, func(options *Options) {
+		options.SkipConsistencyCheck = true
+	})
+	assert.NilError(t, err)
+}
+
+func TestLoadWithEnvFileFormatDetection(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), "app.json")
+	assert.NilError(t, os.WriteFile(envFile, []byte(`{"DB": {"HOST": "localhost"}}`), 0o600))
+	yaml := fmt.Sprintf(`
+name: formats
+services:
+  app:
+    image: alpine
+    env_file: %s
+`, envFile)
+	_, err := LoadWithContext(context.Background(), buildConfigDetails(yaml, nil))
+	assert.ErrorContains(t, err, "failed to read "+envFile)
+
+	p, err := LoadWithContext(context.Background(), buildConfigDetails(yaml, nil), WithEnvFileFormatDetection)
+	assert.NilError(t, err)
+	assert.Equal(t, *p.Services["app"].Environment["DB_HOST"], "localhost")
+}
@@ -0,0 +1,130 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package loader
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/errdefs"
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// mount is a container path a service mounts a volume, tmpfs, secret or config to
+type mount struct {
+	loc    string
+	target string
+	source string
+	// file is set for mounts which target is a file, so that no other mount can be nested
+	file bool
+}
+
+// checkMountTargets checks a service doesn't declare multiple mounts with the same target,
+// nor mounts nested inside a secret or config file
+func checkMountTargets(s types.ServiceConfig) error {
+	var mounts []mount
+	for i, tmpfs := range s.Tmpfs {
+		target, _, _ := strings.Cut(tmpfs, ":")
+		mounts = append(mounts, mount{
+			loc:    fmt.Sprintf("services.%s.tmpfs[%d]", s.Name, i),
+			target: target,
+			source: "tmpfs",
+		})
+	}
+	for i, volume := range s.Volumes {
+		mounts = append(mounts, mount{
+			loc:    fmt.Sprintf("services.%s.volumes[%d]", s.Name, i),
+			target: volume.Target,
+			source: volumeSource(volume),
+		})
+	}
+	for i, secret := range s.Secrets {
+		mounts = append(mounts, mount{
+			loc:    fmt.Sprintf("services.%s.secrets[%d]", s.Name, i),
+			target: fileTarget("/run/secrets", secret.Source, secret.Target),
+			source: fmt.Sprintf("secret %q", secret.Source),
+			file:   true,
+		})
+	}
+	for i, config := range s.Configs {
+		mounts = append(mounts, mount{
+			loc:    fmt.Sprintf("services.%s.configs[%d]", s.Name, i),
+			target: fileTarget("/", config.Source, config.Target),
+			source: fmt.Sprintf("config %q", config.Source),
+			file:   true,
+		})
+	}
+
+	for i, m := range mounts {
+		if m.target == "" {
+			continue
+		}
+		target := path.Clean(m.target)
+		for _, other := range mounts[:i] {
+			if other.target == "" {
+				continue
+			}
+			otherTarget := path.Clean(other.target)
+			switch {
+			case target == otherTarget:
+				return fmt.Errorf("%s: target %s already mounted as %s: %s conflicts with %s: %w",
+					m.loc, m.target, other.loc, m.source, other.source, errdefs.ErrInvalid)
+			case other.file && isNested(target, otherTarget):
+				return fmt.Errorf("%s: target %s is nested in file %s mounted as %s: %s conflicts with %s: %w",
+					m.loc, m.target, other.target, other.loc, m.source, other.source, errdefs.ErrInvalid)
+			case m.file && isNested(otherTarget, target):
+				return fmt.Errorf("%s: target %s is nested in file %s mounted as %s: %s conflicts with %s: %w",
+					other.loc, other.target, m.target, m.loc, other.source, m.source, errdefs.ErrInvalid)
+			}
+		}
+	}
+	return nil
+}
+
+// fileTarget returns the container path a secret or config is mounted to, relative targets
+// and default target being set inside dir
+func fileTarget(dir, source, target string) string {
+	if target == "" {
+		target = source
+	}
+	if path.IsAbs(target) || isWindowsAbs(target) {
+		return target
+	}
+	return path.Join(dir, target)
+}
+
+func isWindowsAbs(p string) bool {
+	return len(p) > 2 && p[1] == ':' && (p[2] == '\\' || p[2] == '/')
+}
+
+func isNested(p, parent string) bool {
+	return strings.HasPrefix(p, strings.TrimSuffix(parent, "/")+"/")
+}
+
+func volumeSource(volume types.ServiceVolumeConfig) string {
+	switch {
+	case volume.Type == types.VolumeTypeTmpfs:
+		return "tmpfs"
+	case volume.Type == types.VolumeTypeVolume && volume.Source == "":
+		return "anonymous volume"
+	case volume.Type == types.VolumeTypeBind:
+		return fmt.Sprintf("bind mount %q", volume.Source)
+	default:
+		return fmt.Sprintf("%s %q", volume.Type, volume.Source)
+	}
+}
@@ -0,0 +1,109 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package loader
+
+import (
+	"fmt"
+
+	"github.com/compose-spec/compose-go/v2/errdefs"
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/compose-spec/compose-go/v2/utils"
+)
+
+// checkResources checks service resource constraints are consistent: service level attributes
+// match deploy.resources, reservations don't exceed limits, and ulimits soft values don't exceed hard ones
+func checkResources(s types.ServiceConfig) error { //nolint:gocyclo
+	var limits, reservations *types.Resource
+	if s.Deploy != nil {
+		limits = s.Deploy.Resources.Limits
+		reservations = s.Deploy.Resources.Reservations
+	}
+
+	if s.CPUS != 0 && limits != nil && limits.NanoCPUs.Value() != s.CPUS {
+		return fmt.Errorf("services.%s: can't set distinct values on 'cpus' and 'deploy.resources.limits.cpus': %w",
+			s.Name, errdefs.ErrInvalid)
+	}
+	if s.MemLimit != 0 && limits != nil && limits.MemoryBytes != s.MemLimit {
+		return fmt.Errorf("services.%s: can't set distinct values on 'mem_limit' and 'deploy.resources.limits.memory': %w",
+			s.Name, errdefs.ErrInvalid)
+	}
+	if s.MemReservation != 0 && reservations != nil && reservations.MemoryBytes != s.MemReservation {
+		return fmt.Errorf("services.%s: can't set distinct values on 'mem_reservation' and 'deploy.resources.reservations.memory': %w",
+			s.Name, errdefs.ErrInvalid)
+	}
+	if s.PidsLimit != 0 && limits != nil && limits.Pids != s.PidsLimit {
+		return fmt.Errorf("services.%s: can't set distinct values on 'pids_limit' and 'deploy.resources.limits.pids': %w",
+			s.Name, errdefs.ErrInvalid)
+	}
+
+	cpus := s.CPUS
+	memLimit := s.MemLimit
+	if limits != nil {
+		if cpus == 0 {
+			cpus = limits.NanoCPUs.Value()
+		}
+		if memLimit == 0 {
+			memLimit = limits.MemoryBytes
+		}
+	}
+	var cpusReservation float32
+	memReservation := s.MemReservation
+	if reservations != nil {
+		cpusReservation = reservations.NanoCPUs.Value()
+		if memReservation == 0 {
+			memReservation = reservations.MemoryBytes
+		}
+	}
+
+	if memLimit > 0 && memReservation > memLimit {
+		return fmt.Errorf("services.%s: memory reservation %d can't exceed memory limit %d: %w",
+			s.Name, memReservation, memLimit, errdefs.ErrInvalid)
+	}
+	if cpus > 0 && cpusReservation > cpus {
+		return fmt.Errorf("services.%s: cpus reservation %g can't exceed cpus limit %g: %w",
+			s.Name, cpusReservation, cpus, errdefs.ErrInvalid)
+	}
+	if s.CPUCount > 0 && cpus > float32(s.CPUCount) {
+		return fmt.Errorf("services.%s: cpus %g can't exceed cpu_count %d: %w",
+			s.Name, cpus, s.CPUCount, errdefs.ErrInvalid)
+	}
+
+	switch {
+	case s.MemSwapLimit == 0, s.MemSwapLimit == -1:
+	case s.MemSwapLimit < 0:
+		return fmt.Errorf("services.%s.memswap_limit: must be -1 (unlimited) or a positive value: %w",
+			s.Name, errdefs.ErrInvalid)
+	case memLimit == 0:
+		return fmt.Errorf("services.%s.memswap_limit: requires a memory limit to be set: %w",
+			s.Name, errdefs.ErrInvalid)
+	case s.MemSwapLimit < memLimit:
+		return fmt.Errorf("services.%s.memswap_limit: %d must be greater than or equal to memory limit %d: %w",
+			s.Name, s.MemSwapLimit, memLimit, errdefs.ErrInvalid)
+	}
+
+	for _, name := range utils.MapKeys(s.Ulimits) {
+		ulimit := s.Ulimits[name]
+		if ulimit == nil || ulimit.Single != 0 {
+			continue
+		}
+		if ulimit.Hard != -1 && (ulimit.Soft == -1 || ulimit.Soft > ulimit.Hard) {
+			return fmt.Errorf("services.%s.ulimits.%s: soft limit %d can't exceed hard limit %d: %w",
+				s.Name, name, ulimit.Soft, ulimit.Hard, errdefs.ErrInvalid)
+		}
+	}
+	return nil
+}
@@ -144,29 +144,8 @@ func checkConsistency(project *types.Project) error { //nolint:gocyclo
 			return fmt.Errorf("services.%s.deploy.replicas: must be greater than or equal to 0", s.Name)
 		}
 
-		if s.CPUS != 0 && s.Deploy != nil {
-			if s.Deploy.Resources.Limits != nil && s.Deploy.Resources.Limits.NanoCPUs.Value() != s.CPUS {
-				return fmt.Errorf("services.%s: can't set distinct values on 'cpus' and 'deploy.resources.limits.cpus': %w",
-					s.Name, errdefs.ErrInvalid)
-			}
-		}
-		if s.MemLimit != 0 && s.Deploy != nil {
-			if s.Deploy.Resources.Limits != nil && s.Deploy.Resources.Limits.MemoryBytes != s.MemLimit {
-				return fmt.Errorf("services.%s: can't set distinct values on 'mem_limit' and 'deploy.resources.limits.memory': %w",
-					s.Name, errdefs.ErrInvalid)
-			}
-		}
-		if s.MemReservation != 0 && s.Deploy != nil {
-			if s.Deploy.Resources.Reservations != nil && s.Deploy.Resources.Reservations.MemoryBytes != s.MemReservation {
-				return fmt.Errorf("services.%s: can't set distinct values on 'mem_reservation' and 'deploy.resources.reservations.memory': %w",
-					s.Name, errdefs.ErrInvalid)
-			}
-		}
-		if s.PidsLimit != 0 && s.Deploy != nil {
-			if s.Deploy.Resources.Limits != nil && s.Deploy.Resources.Limits.Pids != s.PidsLimit {
-				return fmt.Errorf("services.%s: can't set distinct values on 'pids_limit' and 'deploy.resources.limits.pids': %w",
-					s.Name, errdefs.ErrInvalid)
-			}
+		if err := checkResources(s); err != nil {
+			return err
 		}
 
 		if s.GetScale() > 1 && s.ContainerName != "" {
@@ -186,25 +165,16 @@ func checkConsistency(project *types.Project) error { //nolint:gocyclo
 			}
 		}
 
-		mounts := map[string]string{}
-		for i, tmpfs := range s.Tmpfs {
-			loc := fmt.Sprintf("services.%s.tmpfs[%d]", s.Name, i)
-			path, _, _ := strings.Cut(tmpfs, ":")
-			if p, ok := mounts[path]; ok {
-				return fmt.Errorf("%s: target %s already mounted as %s", loc, path, p)
-			}
-			mounts[path] = loc
-		}
-		for i, volume := range s.Volumes {
-			loc := fmt.Sprintf("services.%s.volumes[%d]", s.Name, i)
-			if p, ok := mounts[volume.Target]; ok {
-				return fmt.Errorf("%s: target %s already mounted as %s", loc, volume.Target, p)
-			}
-			mounts[volume.Target] = loc
+		if err := checkMountTargets(s); err != nil {
+			return err
 		}
 
 	}
 
+	if err := checkIPAM(project); err != nil {
+		return err
+	}
+
 	for name, secret := range project.Secrets {
 		if secret.External {
 			continue
@@ -22,6 +22,7 @@ import (
 
 	"gotest.tools/v3/assert"
 
+	"github.com/compose-spec/compose-go/v2/errdefs"
 	"github.com/compose-spec/compose-go/v2/types"
 )
 
@@ -434,7 +435,97 @@ func TestValidateMountConflict(t *testing.T) {
 		},
 	}
 	err := checkConsistency(project)
-	assert.Error(t, err, "services.myservice.volumes[1]: target /conflict already mounted as services.myservice.tmpfs[1]")
+	assert.Error(t, err, `services.myservice.volumes[1]: target /conflict already mounted as services.myservice.tmpfs[1]: bind mount "." conflicts with tmpfs: invalid compose project`)
+}
+
+func TestValidateMountTargets(t *testing.T) {
+	tests := []struct {
+		name     string
+		service  types.ServiceConfig
+		expected string
+	}{
+		{
+			name: "nested volumes",
+			service: types.ServiceConfig{
+				Volumes: []types.ServiceVolumeConfig{
+					{Type: types.VolumeTypeBind, Source: ".", Target: "/app"},
+					{Type: types.VolumeTypeVolume, Target: "/app/node_modules"},
+				},
+				Secrets: []types.ServiceSecretConfig{{Source: "token"}},
+				Configs: []types.ServiceConfigObjConfig{{Source: "app", Target: "/etc/app.conf"}},
+			},
+		},
+		{
+			name: "trailing slash",
+			service: types.ServiceConfig{
+				Volumes: []types.ServiceVolumeConfig{
+					{Type: types.VolumeTypeVolume, Source: "data", Target: "/data/"},
+					{Type: types.VolumeTypeTmpfs, Target: "/data"},
+				},
+			},
+			expected: `services.myservice.volumes[1]: target /data already mounted as services.myservice.volumes[0]: tmpfs conflicts with volume "data": invalid compose project`,
+		},
+		{
+			name: "secret default target",
+			service: types.ServiceConfig{
+				Volumes: []types.ServiceVolumeConfig{
+					{Type: types.VolumeTypeBind, Source: "./token", Target: "/run/secrets/token"},
+				},
+				Secrets: []types.ServiceSecretConfig{{Source: "token"}},
+			},
+			expected: `services.myservice.secrets[0]: target /run/secrets/token already mounted as services.myservice.volumes[0]: secret "token" conflicts with bind mount "./token": invalid compose project`,
+		},
+		{
+			name: "secret relative target",
+			service: types.ServiceConfig{
+				Secrets: []types.ServiceSecretConfig{
+					{Source: "token"},
+					{Source: "other", Target: "token"},
+				},
+			},
+			expected: `services.myservice.secrets[1]: target /run/secrets/token already mounted as services.myservice.secrets[0]: secret "other" conflicts with secret "token": invalid compose project`,
+		},
+		{
+			name: "config default target",
+			service: types.ServiceConfig{
+				Tmpfs:   []string{"/app"},
+				Configs: []types.ServiceConfigObjConfig{{Source: "app"}},
+			},
+			expected: `services.myservice.configs[0]: target /app already mounted as services.myservice.tmpfs[0]: config "app" conflicts with tmpfs: invalid compose project`,
+		},
+		{
+			name: "mount nested in config",
+			service: types.ServiceConfig{
+				Volumes: []types.ServiceVolumeConfig{
+					{Type: types.VolumeTypeVolume, Source: "data", Target: "/etc/app/data"},
+				},
+				Configs: []types.ServiceConfigObjConfig{{Source: "app", Target: "/etc/app"}},
+			},
+			expected: `services.myservice.volumes[0]: target /etc/app/data is nested in file /etc/app mounted as services.myservice.configs[0]: volume "data" conflicts with config "app": invalid compose project`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.service
+			s.Name = "myservice"
+			s.Image = "scratch"
+			project := &types.Project{
+				Services: types.Services{"myservice": s},
+				Volumes:  types.Volumes{"data": {Name: "data"}},
+				Secrets: types.Secrets{
+					"token": {File: "./token"},
+					"other": {File: "./other"},
+				},
+				Configs: types.Configs{"app": {File: "./app.conf"}},
+			}
+			err := checkConsistency(project)
+			if tt.expected == "" {
+				assert.NilError(t, err)
+				return
+			}
+			assert.Error(t, err, tt.expected)
+		})
+	}
 }
 
 func TestValidateNegativeScale(t *testing.T) {
@@ -464,3 +555,300 @@ func TestValidateNegativeScale(t *testing.T) {
 	err = checkConsistency(project)
 	assert.Error(t, err, "services.myservice.deploy.replicas: must be greater than or equal to 0")
 }
+
+func TestValidateIPAM(t *testing.T) {
+	newProject := func(pools []*types.IPAMPool, networks map[string]*types.ServiceNetworkConfig) *types.Project {
+		return &types.Project{
+			Networks: types.Networks{
+				"front": {
+					Ipam: types.IPAMConfig{Config: pools},
+				},
+				"back": {
+					Ipam: types.IPAMConfig{Config: []*types.IPAMPool{{Subnet: "10.1.0.0/16"}}},
+				},
+			},
+			Services: types.Services{
+				"web": {
+					Name:     "web",
+					Image:    "nginx",
+					Networks: networks,
+				},
+				"api": {
+					Name:  "api",
+					Image: "api",
+					Networks: map[string]*types.ServiceNetworkConfig{
+						"front": {Ipv4Address: "172.28.0.10"},
+					},
+				},
+			},
+		}
+	}
+	valid := []*types.IPAMPool{
+		{
+			Subnet:             "172.28.0.0/16",
+			Gateway:            "172.28.0.1",
+			IPRange:            "172.28.5.0/24",
+			AuxiliaryAddresses: types.Mapping{"host1": "172.28.1.5"},
+		},
+		{Subnet: "2001:db8::/64"},
+	}
+
+	tests := []struct {
+		name     string
+		pools    []*types.IPAMPool
+		networks map[string]*types.ServiceNetworkConfig
+		expected string
+	}{
+		{
+			name:  "valid",
+			pools: valid,
+			networks: map[string]*types.ServiceNetworkConfig{
+				"front": {Ipv4Address: "172.28.0.11", Ipv6Address: "2001:db8::11"},
+				"back":  nil,
+			},
+		},
+		{
+			name:     "invalid subnet",
+			pools:    []*types.IPAMPool{{Subnet: "172.28.0.0"}},
+			expected: `networks.front.ipam.config[0]: invalid subnet "172.28.0.0": invalid compose project`,
+		},
+		{
+			name:     "overlapping subnets",
+			pools:    []*types.IPAMPool{{Subnet: "10.0.0.0/8"}},
+			expected: "networks.front.ipam.config[0]: subnet 10.0.0.0/8 overlaps with subnet 10.1.0.0/16 of network back: invalid compose project",
+		},
+		{
+			name:     "gateway outside subnet",
+			pools:    []*types.IPAMPool{{Subnet: "172.28.0.0/16", Gateway: "172.29.0.1"}},
+			expected: "networks.front.ipam.config[0]: gateway 172.29.0.1 is not in subnet 172.28.0.0/16: invalid compose project",
+		},
+		{
+			name:     "ip_range outside subnet",
+			pools:    []*types.IPAMPool{{Subnet: "172.28.0.0/16", IPRange: "172.28.0.0/12"}},
+			expected: "networks.front.ipam.config[0]: ip_range 172.28.0.0/12 is not in subnet 172.28.0.0/16: invalid compose project",
+		},
+		{
+			name:     "invalid aux address",
+			pools:    []*types.IPAMPool{{Subnet: "172.28.0.0/16", AuxiliaryAddresses: types.Mapping{"host1": "foo"}}},
+			expected: `networks.front.ipam.config[0]: invalid aux_addresses host1 "foo": invalid compose project`,
+		},
+		{
+			name:     "aux address is gateway",
+			pools:    []*types.IPAMPool{{Subnet: "172.28.0.0/16", Gateway: "172.28.0.1", AuxiliaryAddresses: types.Mapping{"host1": "172.28.0.1"}}},
+			expected: "networks.front.ipam.config[0]: aux_addresses host1 172.28.0.1 is already assigned to the gateway of network front: invalid compose project",
+		},
+		{
+			name:     "gateway without subnet",
+			pools:    []*types.IPAMPool{{Gateway: "172.28.0.1"}},
+			expected: "networks.front.ipam.config[0]: gateway, ip_range and aux_addresses require a subnet: invalid compose project",
+		},
+		{
+			name:  "static address outside subnets",
+			pools: valid,
+			networks: map[string]*types.ServiceNetworkConfig{
+				"back": {Ipv4Address: "172.28.0.11"},
+			},
+			expected: "services.web.networks.back.ipv4_address: 172.28.0.11 is not in a subnet of network back: invalid compose project",
+		},
+		{
+			name:  "static address family mismatch",
+			pools: valid,
+			networks: map[string]*types.ServiceNetworkConfig{
+				"front": {Ipv4Address: "2001:db8::11"},
+			},
+			expected: `services.web.networks.front.ipv4_address: invalid address "2001:db8::11": invalid compose project`,
+		},
+		{
+			name:  "static address already assigned",
+			pools: valid,
+			networks: map[string]*types.ServiceNetworkConfig{
+				"front": {Ipv4Address: "172.28.0.10"},
+			},
+			expected: "services.web.networks.front.ipv4_address: 172.28.0.10 is already assigned to service api: invalid compose project",
+		},
+		{
+			name:  "static address is gateway",
+			pools: valid,
+			networks: map[string]*types.ServiceNetworkConfig{
+				"front": {Ipv4Address: "172.28.0.1"},
+			},
+			expected: "services.web.networks.front.ipv4_address: 172.28.0.1 is already assigned to the gateway of network front: invalid compose project",
+		},
+		{
+			name: "no declared subnet",
+			networks: map[string]*types.ServiceNetworkConfig{
+				"front": {Ipv4Address: "192.168.0.10"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkConsistency(newProject(tt.pools, tt.networks))
+			if tt.expected == "" {
+				assert.NilError(t, err)
+				return
+			}
+			assert.Error(t, err, tt.expected)
+		})
+	}
+}
+
+func TestValidateIPAMOverlappingDrivers(t *testing.T) {
+	tests := []struct {
+		name     string
+		network  types.NetworkConfig
+		expected string
+	}{
+		{
+			name:     "bridge",
+			network:  types.NetworkConfig{Driver: "bridge"},
+			expected: "networks.lan.ipam.config[0]: subnet 10.1.0.0/16 overlaps with subnet 10.1.0.0/16 of network back: invalid compose project",
+		},
+		{
+			name:    "macvlan",
+			network: types.NetworkConfig{Driver: "macvlan"},
+		},
+		{
+			name:    "ipvlan",
+			network: types.NetworkConfig{Driver: "ipvlan"},
+		},
+		{
+			name:    "external",
+			network: types.NetworkConfig{External: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			network := tt.network
+			network.Ipam = types.IPAMConfig{Config: []*types.IPAMPool{{Subnet: "10.1.0.0/16"}}}
+			project := &types.Project{
+				Networks: types.Networks{
+					"back": {Ipam: types.IPAMConfig{Config: []*types.IPAMPool{{Subnet: "10.1.0.0/16"}}}},
+					"lan":  network,
+				},
+			}
+			err := checkConsistency(project)
+			if tt.expected == "" {
+				assert.NilError(t, err)
+				return
+			}
+			assert.Error(t, err, tt.expected)
+		})
+	}
+}
+
+func TestValidateIPAMStaticAddressReplicas(t *testing.T) {
+	project := &types.Project{
+		Networks: types.Networks{
+			"front": {Ipam: types.IPAMConfig{Config: []*types.IPAMPool{{Subnet: "172.28.0.0/16"}}}},
+		},
+		Services: types.Services{
+			"web": {
+				Name:  "web",
+				Image: "nginx",
+				Scale: ptr(2),
+				Networks: map[string]*types.ServiceNetworkConfig{
+					"front": {Ipv4Address: "172.28.0.10"},
+				},
+			},
+		},
+	}
+	assert.NilError(t, checkConsistency(project))
+}
+
+func TestValidateResources(t *testing.T) {
+	tests := []struct {
+		name     string
+		service  types.ServiceConfig
+		expected string
+	}{
+		{
+			name: "valid",
+			service: types.ServiceConfig{
+				CPUCount:       2,
+				CPUS:           1.5,
+				MemLimit:       1024,
+				MemReservation: 512,
+				MemSwapLimit:   2048,
+				Ulimits: map[string]*types.UlimitsConfig{
+					"nofile":  {Soft: 1024, Hard: 2048},
+					"nproc":   {Single: 65535},
+					"memlock": {Soft: -1, Hard: -1},
+				},
+				Deploy: &types.DeployConfig{Resources: types.Resources{
+					Reservations: &types.Resource{NanoCPUs: 0.5, MemoryBytes: 512},
+				}},
+			},
+		},
+		{
+			name:    "unlimited swap",
+			service: types.ServiceConfig{MemSwapLimit: -1},
+		},
+		{
+			name: "memory reservation exceeds limit",
+			service: types.ServiceConfig{
+				MemReservation: 2048,
+				Deploy: &types.DeployConfig{Resources: types.Resources{
+					Limits: &types.Resource{MemoryBytes: 1024},
+				}},
+			},
+			expected: "services.myservice: memory reservation 2048 can't exceed memory limit 1024: invalid compose project",
+		},
+		{
+			name: "cpus reservation exceeds limit",
+			service: types.ServiceConfig{
+				Deploy: &types.DeployConfig{Resources: types.Resources{
+					Limits:       &types.Resource{NanoCPUs: 0.5},
+					Reservations: &types.Resource{NanoCPUs: 1},
+				}},
+			},
+			expected: "services.myservice: cpus reservation 1 can't exceed cpus limit 0.5: invalid compose project",
+		},
+		{
+			name:     "cpus exceeds cpu_count",
+			service:  types.ServiceConfig{CPUCount: 1, CPUS: 2},
+			expected: "services.myservice: cpus 2 can't exceed cpu_count 1: invalid compose project",
+		},
+		{
+			name:     "memswap lower than mem_limit",
+			service:  types.ServiceConfig{MemLimit: 2048, MemSwapLimit: 1024},
+			expected: "services.myservice.memswap_limit: 1024 must be greater than or equal to memory limit 2048: invalid compose project",
+		},
+		{
+			name:     "memswap without mem_limit",
+			service:  types.ServiceConfig{MemSwapLimit: 1024},
+			expected: "services.myservice.memswap_limit: requires a memory limit to be set: invalid compose project",
+		},
+		{
+			name: "ulimit soft exceeds hard",
+			service: types.ServiceConfig{Ulimits: map[string]*types.UlimitsConfig{
+				"nofile": {Soft: 4096, Hard: 1024},
+			}},
+			expected: "services.myservice.ulimits.nofile: soft limit 4096 can't exceed hard limit 1024: invalid compose project",
+		},
+		{
+			name: "conflicting memory limits",
+			service: types.ServiceConfig{
+				MemLimit: 2048,
+				Deploy: &types.DeployConfig{Resources: types.Resources{
+					Limits: &types.Resource{MemoryBytes: 1024},
+				}},
+			},
+			expected: "services.myservice: can't set distinct values on 'mem_limit' and 'deploy.resources.limits.memory': invalid compose project",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := tt.service
+			s.Name = "myservice"
+			s.Image = "scratch"
+			err := checkConsistency(&types.Project{Services: types.Services{"myservice": s}})
+			if tt.expected == "" {
+				assert.NilError(t, err)
+				return
+			}
+			assert.Error(t, err, tt.expected)
+			assert.Assert(t, errdefs.IsInvalidError(err))
+		})
+	}
+}
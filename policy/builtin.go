@@ -0,0 +1,118 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package policy
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+
+	"github.com/compose-spec/compose-go/v2/tree"
+	"github.com/compose-spec/compose-go/v2/types"
+)
+
+// Built-in rules for common security checks, registered by default
+const (
+	RuleNoPrivileged     = "no-privileged"
+	RuleNoHostNetwork    = "no-host-network"
+	RuleNoHostNamespaces = "no-host-namespaces"
+	RuleNoDockerSocket   = "no-docker-socket"
+	RuleMemoryLimit      = "memory-limit"
+)
+
+func init() {
+	Register(NewRule(RuleNoPrivileged, SeverityError, checkServices(noPrivileged)))
+	Register(NewRule(RuleNoHostNetwork, SeverityError, checkServices(noHostNetwork)))
+	Register(NewRule(RuleNoHostNamespaces, SeverityError, checkServices(noHostNamespaces)))
+	Register(NewRule(RuleNoDockerSocket, SeverityError, checkServices(noDockerSocket)))
+	Register(NewRule(RuleMemoryLimit, SeverityWarning, checkServices(memoryLimit)))
+}
+
+// checkServices adapts a per-service check into a project check, services being checked in name order
+func checkServices(check func(service types.ServiceConfig, p tree.Path) []Finding) func(project *types.Project) []Finding {
+	return func(project *types.Project) []Finding {
+		var findings []Finding
+		for _, name := range project.ServiceNames() {
+			findings = append(findings, check(project.Services[name], tree.NewPath("services").Next(name))...)
+		}
+		return findings
+	}
+}
+
+func noPrivileged(service types.ServiceConfig, p tree.Path) []Finding {
+	if !service.Privileged {
+		return nil
+	}
+	return []Finding{{Path: p.Next("privileged"), Message: "privileged containers are not allowed"}}
+}
+
+func noHostNetwork(service types.ServiceConfig, p tree.Path) []Finding {
+	if service.NetworkMode != "host" {
+		return nil
+	}
+	return []Finding{{Path: p.Next("network_mode"), Message: "host network is not allowed"}}
+}
+
+func noHostNamespaces(service types.ServiceConfig, p tree.Path) []Finding {
+	var findings []Finding
+	for _, ns := range []struct {
+		attribute string
+		value     string
+	}{
+		{attribute: "pid", value: service.Pid},
+		{attribute: "ipc", value: service.Ipc},
+		{attribute: "uts", value: service.Uts},
+		{attribute: "userns_mode", value: service.UserNSMode},
+	} {
+		if ns.value == "host" {
+			findings = append(findings, Finding{
+				Path:    p.Next(ns.attribute),
+				Message: fmt.Sprintf("host %s namespace is not allowed", ns.attribute),
+			})
+		}
+	}
+	return findings
+}
+
+func noDockerSocket(service types.ServiceConfig, p tree.Path) []Finding {
+	var findings []Finding
+	for i, v := range service.Volumes {
+		if v.Type == types.VolumeTypeBind && path.Base(v.Source) == "docker.sock" {
+			findings = append(findings, Finding{
+				Path:    p.Next("volumes").Next(strconv.Itoa(i)),
+				Message: fmt.Sprintf("bind mount of Docker socket %s is not allowed", v.Source),
+			})
+		}
+	}
+	if service.UseAPISocket {
+		findings = append(findings, Finding{
+			Path:    p.Next("use_api_socket"),
+			Message: "access to the Docker API socket is not allowed",
+		})
+	}
+	return findings
+}
+
+func memoryLimit(service types.ServiceConfig, p tree.Path) []Finding {
+	if service.MemLimit > 0 {
+		return nil
+	}
+	if service.Deploy != nil && service.Deploy.Resources.Limits != nil && service.Deploy.Resources.Limits.MemoryBytes > 0 {
+		return nil
+	}
+	return []Finding{{Path: p, Message: "service must set mem_limit or deploy.resources.limits.memory"}}
+}
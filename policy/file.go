@@ -0,0 +1,295 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+
+	"github.com/compose-spec/compose-go/v2/errdefs"
+	"github.com/compose-spec/compose-go/v2/tree"
+	"github.com/compose-spec/compose-go/v2/types"
+	"go.yaml.in/yaml/v4"
+)
+
+// RuleFile is the declarative format for policy rules
+//
+//	rules:
+//	  - name: corp-registry
+//	    severity: error
+//	    select: services.*.image
+//	    when:
+//	      not_matches: ^registry\.corp/
+//	    message: images must be pulled from registry.corp
+type RuleFile struct {
+	Rules []RuleDefinition `yaml:"rules"`
+}
+
+// RuleDefinition declares a rule as a path selector over the compose model, a condition the
+// selected values violate and a severity (defaults to error)
+type RuleDefinition struct {
+	Name        string    `yaml:"name"`
+	Description string    `yaml:"description,omitempty"`
+	Severity    Severity  `yaml:"severity,omitempty"`
+	Select      tree.Path `yaml:"select"`
+	When        Condition `yaml:"when"`
+	Message     string    `yaml:"message,omitempty"`
+}
+
+// Condition describes the selected values which violate a rule. When multiple conditions
+// are set, a value must satisfy all of them to be reported
+type Condition struct {
+	Equals     any    `yaml:"equals,omitempty"`
+	NotEquals  any    `yaml:"not_equals,omitempty"`
+	In         []any  `yaml:"in,omitempty"`
+	NotIn      []any  `yaml:"not_in,omitempty"`
+	Matches    string `yaml:"matches,omitempty"`
+	NotMatches string `yaml:"not_matches,omitempty"`
+	// Missing is a path, relative to the selected value, which is not set
+	Missing tree.Path `yaml:"missing,omitempty"`
+}
+
+func (c Condition) empty() bool {
+	return c.Equals == nil && c.NotEquals == nil && c.In == nil && c.NotIn == nil &&
+		c.Matches == "" && c.NotMatches == "" && c.Missing == ""
+}
+
+// LoadRulesFile reads rules declared by a policy file
+func LoadRulesFile(filename string) ([]Rule, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	rules, err := LoadRules(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+	return rules, nil
+}
+
+// LoadRules reads rules declared using the RuleFile format
+func LoadRules(r io.Reader) ([]Rule, error) {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	var file RuleFile
+	if err := decoder.Decode(&file); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to parse policy rules: %w", err)
+	}
+	var rules []Rule
+	names := map[string]bool{}
+	for _, def := range file.Rules {
+		if names[def.Name] {
+			return nil, fmt.Errorf("rule %q is declared more than once: %w", def.Name, errdefs.ErrInvalid)
+		}
+		names[def.Name] = true
+		rule, err := NewDeclarativeRule(def)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+type declarativeRule struct {
+	def        RuleDefinition
+	matches    *regexp.Regexp
+	notMatches *regexp.Regexp
+}
+
+// NewDeclarativeRule creates a Rule from a RuleDefinition
+func NewDeclarativeRule(def RuleDefinition) (Rule, error) {
+	if def.Name == "" {
+		return nil, fmt.Errorf("rule name is required: %w", errdefs.ErrInvalid)
+	}
+	if def.Select == "" {
+		return nil, fmt.Errorf("rule %q: select is required: %w", def.Name, errdefs.ErrInvalid)
+	}
+	if def.When.empty() {
+		return nil, fmt.Errorf("rule %q: when requires at least one condition: %w", def.Name, errdefs.ErrInvalid)
+	}
+	if def.Severity == "" {
+		def.Severity = SeverityError
+	}
+	if !def.Severity.valid() {
+		return nil, fmt.Errorf("rule %q: invalid severity %q: %w", def.Name, def.Severity, errdefs.ErrInvalid)
+	}
+	rule := declarativeRule{def: def}
+	var err error
+	if def.When.Matches != "" {
+		if rule.matches, err = regexp.Compile(def.When.Matches); err != nil {
+			return nil, fmt.Errorf("rule %q: invalid matches expression: %w", def.Name, err)
+		}
+	}
+	if def.When.NotMatches != "" {
+		if rule.notMatches, err = regexp.Compile(def.When.NotMatches); err != nil {
+			return nil, fmt.Errorf("rule %q: invalid not_matches expression: %w", def.Name, err)
+		}
+	}
+	return rule, nil
+}
+
+func (r declarativeRule) Name() string {
+	return r.def.Name
+}
+
+func (r declarativeRule) Evaluate(project *types.Project) []Finding {
+	dict, err := projectDict(project)
+	if err != nil {
+		return []Finding{{
+			Rule:     r.def.Name,
+			Severity: r.def.Severity,
+			Message:  fmt.Sprintf("failed to evaluate rule: %s", err),
+		}}
+	}
+	var findings []Finding
+	selectValues(dict, tree.NewPath(), r.def.Select, func(p tree.Path, value any) {
+		if r.violated(value) {
+			findings = append(findings, Finding{
+				Rule:     r.def.Name,
+				Severity: r.def.Severity,
+				Path:     p,
+				Message:  r.message(),
+			})
+		}
+	})
+	return findings
+}
+
+func (r declarativeRule) message() string {
+	switch {
+	case r.def.Message != "":
+		return r.def.Message
+	case r.def.Description != "":
+		return r.def.Description
+	default:
+		return fmt.Sprintf("violates rule %s", r.def.Name)
+	}
+}
+
+func (r declarativeRule) violated(value any) bool {
+	when := r.def.When
+	if when.Equals != nil && !equalValues(value, when.Equals) {
+		return false
+	}
+	if when.NotEquals != nil && equalValues(value, when.NotEquals) {
+		return false
+	}
+	if when.In != nil && !containsValue(when.In, value) {
+		return false
+	}
+	if when.NotIn != nil && containsValue(when.NotIn, value) {
+		return false
+	}
+	if r.matches != nil {
+		s, ok := scalar(value)
+		if !ok || !r.matches.MatchString(s) {
+			return false
+		}
+	}
+	if r.notMatches != nil {
+		s, ok := scalar(value)
+		if !ok || r.notMatches.MatchString(s) {
+			return false
+		}
+	}
+	if when.Missing != "" && lookupValue(value, when.Missing.Parts()) {
+		return false
+	}
+	return true
+}
+
+// projectDict returns the compose model as a generic yaml tree, for selectors to apply on
+// attribute names as declared in compose files
+func projectDict(project *types.Project) (map[string]any, error) {
+	b, err := project.MarshalYAML()
+	if err != nil {
+		return nil, err
+	}
+	var dict map[string]any
+	err = yaml.Unmarshal(b, &dict)
+	return dict, err
+}
+
+func selectValues(value any, p tree.Path, pattern tree.Path, fn func(p tree.Path, value any)) {
+	if p.Matches(pattern) {
+		fn(p, value)
+		return
+	}
+	if p != "" && len(p.Parts()) >= len(pattern.Parts()) {
+		return
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		for k, e := range v {
+			selectValues(e, p.Next(k), pattern, fn)
+		}
+	case []any:
+		for i, e := range v {
+			selectValues(e, p.Next(strconv.Itoa(i)), pattern, fn)
+		}
+	}
+}
+
+func lookupValue(value any, path []string) bool {
+	if len(path) == 0 {
+		return value != nil
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		e, ok := v[path[0]]
+		return ok && lookupValue(e, path[1:])
+	case []any:
+		i, err := strconv.Atoi(path[0])
+		return err == nil && i >= 0 && i < len(v) && lookupValue(v[i], path[1:])
+	}
+	return false
+}
+
+func scalar(value any) (string, bool) {
+	switch value.(type) {
+	case map[string]any, []any, nil:
+		return "", false
+	}
+	return fmt.Sprint(value), true
+}
+
+// equalValues compares values from the compose model and from the rule file, scalars being
+// compared by their string representation so that `8080` and `"8080"` are considered equal
+func equalValues(a, b any) bool {
+	sa, okA := scalar(a)
+	sb, okB := scalar(b)
+	if okA && okB {
+		return sa == sb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func containsValue(values []any, value any) bool {
+	for _, v := range values {
+		if equalValues(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package policy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/errdefs"
+	"gotest.tools/v3/assert"
+)
+
+func TestDeclarativeRules(t *testing.T) {
+	rules, err := LoadRules(strings.NewReader(`
+rules:
+  - name: corp-registry
+    select: services.*.image
+    when:
+      not_matches: ^registry\.corp/
+    message: images must be pulled from registry.corp
+  - name: team-label
+    severity: warning
+    description: services must declare a team label
+    select: services.*
+    when:
+      missing: labels.team
+  - name: no-host-network
+    select: services.*.network_mode
+    when:
+      in: [host, "none"]
+  - name: exposed-ports
+    severity: info
+    select: services.*.ports.*.published
+    when:
+      equals: 8080
+`))
+	assert.NilError(t, err)
+	assert.Equal(t, len(rules), 4)
+
+	project, filename := loadProject(t, `
+services:
+  web:
+    image: nginx
+    network_mode: host
+    ports:
+      - 8080:80
+    labels:
+      team: frontend
+  api:
+    image: registry.corp/api
+`)
+	findings := Evaluate(project, rules...)
+	assert.NilError(t, findings.LocateFiles(filename))
+	var got []string
+	for _, f := range findings {
+		got = append(got, strings.TrimPrefix(f.String(), filename+":"))
+	}
+	assert.DeepEqual(t, got, []string{
+		"10:3: warning [team-label] services.api: services must declare a team label",
+		"4:5: error [corp-registry] services.web.image: images must be pulled from registry.corp",
+		"5:5: error [no-host-network] services.web.network_mode: violates rule no-host-network",
+		"info [exposed-ports] services.web.ports.0.published: violates rule exposed-ports",
+	})
+}
+
+func TestInvalidRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    string
+		expected string
+	}{
+		{
+			name: "missing select",
+			rules: `
+rules:
+  - name: test
+    when:
+      equals: true`,
+			expected: `rule "test": select is required: invalid compose project`,
+		},
+		{
+			name: "missing condition",
+			rules: `
+rules:
+  - name: test
+    select: services.*.privileged`,
+			expected: `rule "test": when requires at least one condition: invalid compose project`,
+		},
+		{
+			name: "invalid severity",
+			rules: `
+rules:
+  - name: test
+    severity: fatal
+    select: services.*.privileged
+    when:
+      equals: true`,
+			expected: `rule "test": invalid severity "fatal": invalid compose project`,
+		},
+		{
+			name: "duplicate",
+			rules: `
+rules:
+  - name: test
+    select: services.*.privileged
+    when:
+      equals: true
+  - name: test
+    select: services.*.privileged
+    when:
+      equals: true`,
+			expected: `rule "test" is declared more than once: invalid compose project`,
+		},
+		{
+			name: "invalid expression",
+			rules: `
+rules:
+  - name: test
+    select: services.*.image
+    when:
+      matches: "(["`,
+			expected: "rule \"test\": invalid matches expression: error parsing regexp: missing closing ]: `[`",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadRules(strings.NewReader(tt.rules))
+			assert.Error(t, err, tt.expected)
+		})
+	}
+
+	_, err := LoadRules(strings.NewReader("rules:\n  - name: test\n    unknown: true\n"))
+	assert.ErrorContains(t, err, "field unknown not found")
+	_, err = LoadRules(strings.NewReader("rules:\n  - select: services\n"))
+	assert.Assert(t, errdefs.IsInvalidError(err))
+}
@@ -0,0 +1,182 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package policy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/tree"
+	"github.com/compose-spec/compose-go/v2/types"
+	"go.yaml.in/yaml/v4"
+)
+
+// Severity qualifies the impact of a policy violation
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+func (s Severity) valid() bool {
+	switch s {
+	case SeverityError, SeverityWarning, SeverityInfo:
+		return true
+	}
+	return false
+}
+
+// Finding is a policy violation for an attribute of the compose model
+type Finding struct {
+	Rule     string
+	Severity Severity
+	Path     tree.Path
+	Message  string
+	// Filename, Line and Column are set by Locate when the attribute is found in a compose file
+	Filename string
+	Line     int
+	Column   int
+}
+
+func (f Finding) String() string {
+	var sb strings.Builder
+	if f.Filename != "" {
+		sb.WriteString(fmt.Sprintf("%s:%d:%d: ", f.Filename, f.Line, f.Column))
+	}
+	sb.WriteString(fmt.Sprintf("%s [%s] ", f.Severity, f.Rule))
+	if f.Path != "" {
+		sb.WriteString(f.Path.String() + ": ")
+	}
+	sb.WriteString(f.Message)
+	return sb.String()
+}
+
+// Findings is a list of policy violations
+type Findings []Finding
+
+// HasErrors reports whether any of the findings has SeverityError
+func (fs Findings) HasErrors() bool {
+	return slices.ContainsFunc(fs, func(f Finding) bool {
+		return f.Severity == SeverityError
+	})
+}
+
+// Locate sets the source position of findings for the attributes found in a yaml document.
+// When a project is loaded from multiple files, Locate is expected to be called for each file
+// in loading order, so that findings point to the file which set the effective value.
+// Sequence items are matched by their index in the model, which only matches the index in the
+// yaml document as long as the sequence isn't merged with another file, reset with `!reset` or
+// replaced with `!override`. Such findings may point to the wrong item, or not be located
+func (fs Findings) Locate(filename string, root *yaml.Node) {
+	for i, f := range fs {
+		node := f.Path.Node(root)
+		if node == nil {
+			continue
+		}
+		fs[i].Filename = filename
+		fs[i].Line = node.Line
+		fs[i].Column = node.Column
+	}
+}
+
+// LocateFiles parses compose files in loading order and sets the source position of findings
+func (fs Findings) LocateFiles(filenames ...string) error {
+	for _, filename := range filenames {
+		root, err := tree.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		fs.Locate(filename, root)
+	}
+	return nil
+}
+
+// Rule checks a compose project against an organization policy
+type Rule interface {
+	// Name uniquely identifies the rule
+	Name() string
+	// Evaluate returns the violations of the rule by project
+	Evaluate(project *types.Project) []Finding
+}
+
+type funcRule struct {
+	name     string
+	severity Severity
+	check    func(project *types.Project) []Finding
+}
+
+// NewRule creates a Rule from a check function. Rule name and severity are set on the findings
+// returned by check, which only need to set Path and Message
+func NewRule(name string, severity Severity, check func(project *types.Project) []Finding) Rule {
+	return funcRule{name: name, severity: severity, check: check}
+}
+
+func (r funcRule) Name() string {
+	return r.name
+}
+
+func (r funcRule) Evaluate(project *types.Project) []Finding {
+	findings := r.check(project)
+	for i := range findings {
+		findings[i].Rule = r.name
+		findings[i].Severity = r.severity
+	}
+	return findings
+}
+
+var rules = map[string]Rule{}
+
+// Register makes a Rule available by name, replacing any rule previously registered with the same name
+func Register(rule Rule) {
+	rules[rule.Name()] = rule
+}
+
+// Lookup returns the Rule registered by name
+func Lookup(name string) (Rule, bool) {
+	r, ok := rules[name]
+	return r, ok
+}
+
+// Rules returns the registered rules sorted by name
+func Rules() []Rule {
+	var all []Rule
+	for _, r := range rules {
+		all = append(all, r)
+	}
+	slices.SortFunc(all, func(a, b Rule) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+	return all
+}
+
+// Evaluate checks project against rules, returning findings sorted by path and rule name
+func Evaluate(project *types.Project, rules ...Rule) Findings {
+	var findings Findings
+	for _, r := range rules {
+		findings = append(findings, r.Evaluate(project)...)
+	}
+	slices.SortStableFunc(findings, func(a, b Finding) int {
+		if c := slices.Compare(a.Path.Parts(), b.Path.Parts()); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Rule, b.Rule)
+	})
+	return findings
+}
@@ -0,0 +1,108 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package policy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/loader"
+	"github.com/compose-spec/compose-go/v2/tree"
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func loadProject(t *testing.T, yaml string) (*types.Project, string) {
+	t.Helper()
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "compose.yaml")
+	assert.NilError(t, os.WriteFile(filename, []byte(yaml), 0o600))
+	project, err := loader.LoadWithContext(context.TODO(), types.ConfigDetails{
+		WorkingDir:  dir,
+		ConfigFiles: []types.ConfigFile{{Filename: filename}},
+		Environment: map[string]string{},
+	}, func(options *loader.Options) {
+		options.SetProjectName("test", true)
+	})
+	assert.NilError(t, err)
+	return project, filename
+}
+
+func TestBuiltinRules(t *testing.T) {
+	project, _ := loadProject(t, `
+services:
+  web:
+    image: nginx
+    privileged: true
+    network_mode: host
+    pid: host
+    volumes:
+      - ./html:/usr/share/nginx/html
+      - /var/run/docker.sock:/var/run/docker.sock
+  db:
+    image: postgres
+    mem_limit: 512m
+`)
+	findings := Evaluate(project, Rules()...)
+	var got []string
+	for _, f := range findings {
+		got = append(got, f.String())
+	}
+	assert.DeepEqual(t, got, []string{
+		"warning [memory-limit] services.web: service must set mem_limit or deploy.resources.limits.memory",
+		"error [no-host-network] services.web.network_mode: host network is not allowed",
+		"error [no-host-namespaces] services.web.pid: host pid namespace is not allowed",
+		"error [no-privileged] services.web.privileged: privileged containers are not allowed",
+		"error [no-docker-socket] services.web.volumes.1: bind mount of Docker socket /var/run/docker.sock is not allowed",
+	})
+	assert.Assert(t, findings.HasErrors())
+}
+
+func TestLocateFindings(t *testing.T) {
+	project, filename := loadProject(t, `
+services:
+  web:
+    image: nginx
+    privileged: true
+`)
+	findings := Evaluate(project, NewRule("test", SeverityWarning, func(project *types.Project) []Finding {
+		return []Finding{
+			{Path: tree.NewPath("services", "web", "privileged"), Message: "not allowed"},
+			{Path: tree.NewPath("services", "web", "user"), Message: "must be set"},
+		}
+	}))
+	assert.NilError(t, findings.LocateFiles(filename))
+	assert.Equal(t, findings[0].String(), filename+":5:5: warning [test] services.web.privileged: not allowed")
+	assert.Equal(t, findings[1].String(), "warning [test] services.web.user: must be set")
+	assert.Assert(t, !findings.HasErrors())
+}
+
+func TestRegisterRule(t *testing.T) {
+	rule := NewRule("custom", SeverityInfo, func(project *types.Project) []Finding {
+		return nil
+	})
+	Register(rule)
+	t.Cleanup(func() {
+		delete(rules, "custom")
+	})
+	r, ok := Lookup("custom")
+	assert.Assert(t, ok)
+	assert.Equal(t, r.Name(), "custom")
+	assert.Equal(t, len(Rules()), 6)
+}
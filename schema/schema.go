@@ -103,13 +103,19 @@ func Validate(config map[string]interface{}) error {
 	err = schema.Validate(raw)
 	var verr *jsonschema.ValidationError
 	if ok := errors.As(err, &verr); ok {
-		return validationError{getMostSpecificError(verr)}
+		verr = getMostSpecificError(verr)
+		var suggestions []string
+		if k, ok := verr.ErrorKind.(*kind.AdditionalProperties); ok && len(k.Properties) == 1 {
+			suggestions = suggest(k.Properties[0], allowedProperties(schema, verr.SchemaURL))
+		}
+		return validationError{err: verr, suggestions: suggestions}
 	}
 	return err
 }
 
 type validationError struct {
-	err *jsonschema.ValidationError
+	err         *jsonschema.ValidationError
+	suggestions []string
 }
 
 func (e validationError) Error() string {
@@ -122,6 +128,10 @@ func (e validationError) Error() string {
 		return fmt.Sprintf("%s must be greater than or equal to %s", path, k.Want.Num())
 	case *kind.Maximum:
 		return fmt.Sprintf("%s must be less than or equal to %s", path, k.Want.Num())
+	case *kind.AdditionalProperties:
+		if len(e.suggestions) > 0 {
+			return fmt.Sprintf("%s %s, did you mean %s?", path, k.LocalizedString(p), quoteAll(e.suggestions))
+		}
 	}
 	return fmt.Sprintf("%s %s", path, e.err.ErrorKind.LocalizedString(p))
 }
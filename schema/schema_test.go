@@ -18,6 +18,7 @@ package schema
 
 import (
 	"os"
+	"slices"
 	"strings"
 	"sync"
 	"testing"
@@ -285,3 +286,58 @@ func TestSchema(t *testing.T) {
 	_, err = compiler.Compile("compose-spec.json")
 	assert.NilError(t, err)
 }
+
+func TestValidateSuggestion(t *testing.T) {
+	config := map[string]any{
+		"services": map[string]any{
+			"foo": map[string]any{
+				"image":      "busybox",
+				"enviroment": []any{"FOO=BAR"},
+			},
+		},
+	}
+
+	err := Validate(config)
+	assert.Error(t, err, "services.foo additional properties 'enviroment' not allowed, did you mean 'environment'?")
+}
+
+func TestValidateAll(t *testing.T) {
+	source := `
+services:
+  foo:
+    image: busybox
+    enviroment:
+      - FOO=BAR
+    ports: true
+    build:
+      context: .
+      dockerfil: Dockerfile
+  bar:
+    image: busybox
+    restart: 1
+    build: 1
+unknown: true
+`
+	var config map[string]any
+	assert.NilError(t, yaml.Unmarshal([]byte(source), &config))
+	violations, err := ValidateAll(config)
+	assert.NilError(t, err)
+
+	var root yaml.Node
+	assert.NilError(t, yaml.Unmarshal([]byte(source), &root))
+	violations.Locate("compose.yaml", &root)
+
+	var got []string
+	for _, v := range violations {
+		got = append(got, v.InstancePath+" "+v.Keyword+": "+v.Error())
+	}
+	slices.Sort(got)
+	assert.DeepEqual(t, []string{
+		"/services/bar/build type: compose.yaml:14:5: services.bar.build must be a mapping or string",
+		"/services/bar/restart type: compose.yaml:13:5: services.bar.restart must be a string",
+		"/services/foo/build/dockerfil additionalProperties: compose.yaml:10:7: services.foo.build additional properties 'dockerfil' not allowed, did you mean 'dockerfile'?",
+		"/services/foo/enviroment additionalProperties: compose.yaml:5:5: services.foo additional properties 'enviroment' not allowed, did you mean 'environment'?",
+		"/services/foo/ports type: compose.yaml:7:5: services.foo.ports must be a array",
+		"/unknown additionalProperties: compose.yaml:15:1: additional properties 'unknown' not allowed",
+	}, got)
+}
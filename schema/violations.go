@@ -0,0 +1,340 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/tree"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"github.com/santhosh-tekuri/jsonschema/v6/kind"
+	"go.yaml.in/yaml/v4"
+)
+
+// Violation is a single JSON schema violation by a compose model
+type Violation struct {
+	// InstancePath is the JSON pointer to the invalid value, e.g. `/services/web/enviroment`
+	InstancePath string
+	// Keyword is the JSON schema keyword the value doesn't satisfy, e.g. `additionalProperties`
+	Keyword string
+	// Message describes the violation, without path
+	Message string
+	// Suggestions are the allowed properties close to an unexpected one
+	Suggestions []string
+	// Filename, Line and Column locate the invalid value in source file, when available
+	Filename string
+	Line     int
+	Column   int
+}
+
+func (v Violation) Error() string {
+	var sb strings.Builder
+	if v.Filename != "" {
+		sb.WriteString(fmt.Sprintf("%s:%d:%d: ", v.Filename, v.Line, v.Column))
+	}
+	if path := v.path(); path != "" {
+		sb.WriteString(path + " ")
+	}
+	sb.WriteString(v.Message)
+	if len(v.Suggestions) > 0 {
+		sb.WriteString(fmt.Sprintf(", did you mean %s?", quoteAll(v.Suggestions)))
+	}
+	return sb.String()
+}
+
+// path returns the dotted path to the invalid value, as used by Validate errors
+func (v Violation) path() string {
+	tokens := pointerTokens(v.InstancePath)
+	if v.Keyword == "additionalProperties" && len(tokens) > 0 {
+		// report unexpected property on parent, like Validate does
+		tokens = tokens[:len(tokens)-1]
+	}
+	return strings.Join(tokens, ".")
+}
+
+// Violations is a list of Violation
+type Violations []Violation
+
+func (vs Violations) Error() string {
+	msgs := make([]string, len(vs))
+	for i, v := range vs {
+		msgs[i] = v.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Locate sets the source position of violations for the values found in a yaml document
+func (vs Violations) Locate(filename string, root *yaml.Node) {
+	for i, v := range vs {
+		node := tree.LookupNode(root, pointerTokens(v.InstancePath)...)
+		if node == nil {
+			continue
+		}
+		vs[i].Filename = filename
+		vs[i].Line = node.Line
+		vs[i].Column = node.Column
+	}
+}
+
+// ValidateAll validates the configuration against the jsonschema and returns all violations.
+// Violations are deduplicated and, for alternatives (oneOf, anyOf), only the most specific
+// alternatives are reported.
+func ValidateAll(config map[string]interface{}) (Violations, error) {
+	schema, err := compileSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	marshaled, err := json.Marshal(config)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]interface{}
+	err = json.Unmarshal(marshaled, &raw)
+	if err != nil {
+		return nil, err
+	}
+
+	err = schema.Validate(raw)
+	var verr *jsonschema.ValidationError
+	if !errors.As(err, &verr) {
+		return nil, err
+	}
+
+	var violations Violations
+	seen := map[string]int{}
+	// wanted collects the types allowed by alternatives for a value, by violation index
+	wanted := map[int][]string{}
+	for _, leaf := range collectLeaves(verr) {
+		for _, v := range newViolations(schema, leaf) {
+			key := v.InstancePath + "|" + v.Keyword + "|" + v.Message
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			if k, ok := leaf.ErrorKind.(*kind.Type); ok {
+				if i, ok := seen[v.InstancePath+"|type"]; ok {
+					// merge alternative types for the same value
+					wanted[i] = append(wanted[i], k.Want...)
+					violations[i].Message = typeMessage(wanted[i])
+					continue
+				}
+				seen[v.InstancePath+"|type"] = len(violations)
+				wanted[len(violations)] = slices.Clone(k.Want)
+			}
+			seen[key] = len(violations)
+			violations = append(violations, v)
+		}
+	}
+	return violations, nil
+}
+
+// collectLeaves returns the leaf errors in validation tree. For oneOf/anyOf, only alternatives
+// with the most specific errors are kept, like getMostSpecificError does
+func collectLeaves(err *jsonschema.ValidationError) []*jsonschema.ValidationError {
+	if len(err.Causes) == 0 {
+		return []*jsonschema.ValidationError{err}
+	}
+	var leaves []*jsonschema.ValidationError
+	switch err.ErrorKind.(type) {
+	case *kind.OneOf, *kind.AnyOf:
+		best := -1
+		var alternatives [][]*jsonschema.ValidationError
+		for _, cause := range err.Causes {
+			l := collectLeaves(cause)
+			alternatives = append(alternatives, l)
+			best = max(best, maxSpecificity(l))
+		}
+		for _, l := range alternatives {
+			if maxSpecificity(l) == best {
+				leaves = append(leaves, l...)
+			}
+		}
+	default:
+		for _, cause := range err.Causes {
+			leaves = append(leaves, collectLeaves(cause)...)
+		}
+	}
+	return leaves
+}
+
+func maxSpecificity(errs []*jsonschema.ValidationError) int {
+	m := -1
+	for _, err := range errs {
+		m = max(m, specificity(err))
+	}
+	return m
+}
+
+func newViolations(schema *jsonschema.Schema, err *jsonschema.ValidationError) []Violation {
+	keyword := strings.Join(err.ErrorKind.KeywordPath(), "/")
+	if k, ok := err.ErrorKind.(*kind.AdditionalProperties); ok {
+		allowed := allowedProperties(schema, err.SchemaURL)
+		var violations []Violation
+		for _, property := range k.Properties {
+			violations = append(violations, Violation{
+				InstancePath: toPointer(append(slices.Clone(err.InstanceLocation), property)),
+				Keyword:      keyword,
+				Message:      fmt.Sprintf("additional properties '%s' not allowed", property),
+				Suggestions:  suggest(property, allowed),
+			})
+		}
+		return violations
+	}
+	message := validationError{err: err}.Error()
+	if path := strings.Join(err.InstanceLocation, "."); path != "" {
+		message = strings.TrimPrefix(message, path+" ")
+	}
+	return []Violation{{
+		InstancePath: toPointer(err.InstanceLocation),
+		Keyword:      keyword,
+		Message:      message,
+	}}
+}
+
+// typeMessage describes a type violation for a value which can be any of the wanted types
+func typeMessage(want []string) string {
+	types := slices.Clone(want)
+	slices.Sort(types)
+	return "must be a " + humanReadableType(slices.Compact(types)...)
+}
+
+// allowedProperties returns the properties declared by schema at location
+func allowedProperties(root *jsonschema.Schema, location string) []string {
+	s := findSchema(root, location, map[*jsonschema.Schema]bool{})
+	if s == nil {
+		return nil
+	}
+	var properties []string
+	for p := range s.Properties {
+		properties = append(properties, p)
+	}
+	slices.Sort(properties)
+	return properties
+}
+
+func findSchema(s *jsonschema.Schema, location string, visited map[*jsonschema.Schema]bool) *jsonschema.Schema {
+	if s == nil || visited[s] {
+		return nil
+	}
+	visited[s] = true
+	if s.Location == location {
+		return s
+	}
+	children := []*jsonschema.Schema{s.Ref, s.Not, s.If, s.Then, s.Else, s.Items2020, s.Contains, s.PropertyNames}
+	children = append(children, s.AllOf...)
+	children = append(children, s.AnyOf...)
+	children = append(children, s.OneOf...)
+	children = append(children, s.PrefixItems...)
+	for _, p := range s.Properties {
+		children = append(children, p)
+	}
+	for _, p := range s.PatternProperties {
+		children = append(children, p)
+	}
+	if p, ok := s.AdditionalProperties.(*jsonschema.Schema); ok {
+		children = append(children, p)
+	}
+	if p, ok := s.AdditionalItems.(*jsonschema.Schema); ok {
+		children = append(children, p)
+	}
+	switch items := s.Items.(type) {
+	case *jsonschema.Schema:
+		children = append(children, items)
+	case []*jsonschema.Schema:
+		children = append(children, items...)
+	}
+	for _, child := range children {
+		if found := findSchema(child, location, visited); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// suggest returns candidates close enough to value to be a likely typo
+func suggest(value string, candidates []string) []string {
+	threshold := max(1, len(value)/3)
+	best := threshold + 1
+	var suggestions []string
+	for _, c := range candidates {
+		d := levenshtein(value, c)
+		switch {
+		case d < best:
+			best = d
+			suggestions = []string{c}
+		case d == best:
+			suggestions = append(suggestions, c)
+		}
+	}
+	return suggestions
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+func quoteAll(values []string) string {
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = "'" + v + "'"
+	}
+	return strings.Join(quoted, " or ")
+}
+
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+func toPointer(tokens []string) string {
+	var sb strings.Builder
+	for _, t := range tokens {
+		sb.WriteString("/" + pointerEscaper.Replace(t))
+	}
+	return sb.String()
+}
+
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+func pointerTokens(pointer string) []string {
+	if pointer == "" {
+		return nil
+	}
+	tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, t := range tokens {
+		tokens[i] = pointerUnescaper.Replace(t)
+	}
+	return tokens
+}
@@ -0,0 +1,87 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package tree
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"go.yaml.in/yaml/v4"
+)
+
+// ReadFile parses a yaml file into a node tree, keeping the source position of values
+func ReadFile(filename string) (*yaml.Node, error) {
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	var root yaml.Node
+	if err := yaml.Unmarshal(b, &root); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+	return &root, nil
+}
+
+// Node returns the yaml node declaring the value at path, see LookupNode
+func (p Path) Node(root *yaml.Node) *yaml.Node {
+	return LookupNode(root, p.Keys()...)
+}
+
+// LookupNode returns the yaml node for the value at keys. Mapping entries resolve to the key node, so the
+// position points to the attribute declaration. Sequence items are addressed by index, or by value for
+// lists of names in short syntax. Indexes are relative to the yaml document, not to the merged model
+func LookupNode(node *yaml.Node, keys ...string) *yaml.Node {
+	if node == nil {
+		return nil
+	}
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if len(keys) == 0 {
+		return node
+	}
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value != keys[0] {
+				continue
+			}
+			if len(keys) == 1 {
+				return node.Content[i]
+			}
+			return LookupNode(node.Content[i+1], keys[1:]...)
+		}
+	case yaml.SequenceNode:
+		if index, err := strconv.Atoi(keys[0]); err == nil {
+			if index < 0 || index >= len(node.Content) {
+				return nil
+			}
+			return LookupNode(node.Content[index], keys[1:]...)
+		}
+		// short syntax declares a list of names where the model has a mapping, e.g. `depends_on: [db]`
+		for _, item := range node.Content {
+			if len(keys) == 1 && item.Kind == yaml.ScalarNode && item.Value == keys[0] {
+				return item
+			}
+		}
+	}
+	return nil
+}
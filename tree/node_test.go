@@ -0,0 +1,62 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package tree
+
+import (
+	"testing"
+
+	"go.yaml.in/yaml/v4"
+	"gotest.tools/v3/assert"
+)
+
+func TestLookupNode(t *testing.T) {
+	var root yaml.Node
+	assert.NilError(t, yaml.Unmarshal([]byte(`
+services:
+  web.app:
+    image: nginx
+    ports:
+      - 80:80
+      - 443:443
+  db: &db
+    image: postgres
+    depends_on: [cache, queue]
+  replica: *db
+`), &root))
+
+	for path, expected := range map[Path][2]int{
+		NewPath("services").Next("web.app"):                         {3, 3},
+		NewPath("services").Next("web.app").Next("image"):           {4, 5},
+		NewPath("services").Next("web.app").Next("ports").Next("1"): {7, 9},
+		NewPath("services").Next("replica").Next("image"):           {9, 5},
+	} {
+		node := path.Node(&root)
+		assert.Assert(t, node != nil, path)
+		assert.DeepEqual(t, [2]int{node.Line, node.Column}, expected)
+	}
+	for _, path := range []Path{
+		NewPath("services", "web", "app"),
+		NewPath("services").Next("web.app").Next("ports").Next("2"),
+		NewPath("services").Next("db").Next("image").Next("tag"),
+	} {
+		assert.Assert(t, path.Node(&root) == nil, path)
+	}
+}
+
+func TestPathKeys(t *testing.T) {
+	assert.DeepEqual(t, NewPath("services").Next("web.app").Next("image").Keys(), []string{"services", "web.app", "image"})
+}
@@ -22,6 +22,9 @@ import (
 
 const pathSeparator = "."
 
+// escapedSeparator replaces pathSeparator in keys, so a key can contain a dot
+const escapedSeparator = "👻"
+
 // PathMatchAll is a token used as part of a Path to match any key at that level
 // in the nested structure
 const PathMatchAll = "*"
@@ -29,6 +32,10 @@ const PathMatchAll = "*"
 // PathMatchList is a token used as part of a Path to match items in a list
 const PathMatchList = "[]"
 
+// PathMatchExtension is a token used as part of a Path to match any `x-*` extension key
+// at that level in the nested structure
+const PathMatchExtension = "x-*"
+
 // Path is a dotted path of keys to a value in a nested mapping structure. A *
 // section in a path will match any key in the mapping structure.
 type Path string
@@ -43,7 +50,7 @@ func (p Path) Next(part string) Path {
 	if p == "" {
 		return Path(part)
 	}
-	part = strings.ReplaceAll(part, pathSeparator, "👻")
+	part = strings.ReplaceAll(part, pathSeparator, escapedSeparator)
 	return Path(string(p) + pathSeparator + part)
 }
 
@@ -62,6 +69,11 @@ func (p Path) Matches(pattern Path) bool {
 		switch patternParts[index] {
 		case PathMatchAll, part:
 			continue
+		case PathMatchExtension:
+			if strings.HasPrefix(part, "x-") {
+				continue
+			}
+			return false
 		default:
 			return false
 		}
@@ -69,6 +81,15 @@ func (p Path) Matches(pattern Path) bool {
 	return true
 }
 
+// Keys returns the keys in path, restoring separators escaped by Next
+func (p Path) Keys() []string {
+	parts := p.Parts()
+	for i, part := range parts {
+		parts[i] = strings.ReplaceAll(part, escapedSeparator, pathSeparator)
+	}
+	return parts
+}
+
 func (p Path) Last() string {
 	parts := p.Parts()
 	return parts[len(parts)-1]
@@ -83,5 +104,5 @@ func (p Path) Parent() Path {
 }
 
 func (p Path) String() string {
-	return strings.ReplaceAll(string(p), "👻", pathSeparator)
+	return strings.ReplaceAll(string(p), escapedSeparator, pathSeparator)
 }
@@ -56,6 +56,17 @@ func TestPathMatches(t *testing.T) {
 			pattern:  NewPath("one", "*", "three"),
 			expected: true,
 		},
+		{
+			doc:      "pattern match with extension part",
+			path:     NewPath("one", "x-two", "three"),
+			pattern:  NewPath("one", PathMatchExtension, "three"),
+			expected: true,
+		},
+		{
+			doc:     "pattern mismatch with extension part",
+			path:    NewPath("one", "two", "three"),
+			pattern: NewPath("one", PathMatchExtension, "three"),
+		},
 		{
 			doc:      "pattern match",
 			path:     NewPath("one", "two", "three"),
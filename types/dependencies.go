@@ -0,0 +1,148 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package types
+
+import (
+	"slices"
+	"strings"
+)
+
+// DependencyKind is the attribute a dependency between services is declared by
+type DependencyKind string
+
+const (
+	DependencyDependsOn          DependencyKind = "depends_on"
+	DependencyNetworkMode        DependencyKind = "network_mode"
+	DependencyIpc                DependencyKind = "ipc"
+	DependencyPid                DependencyKind = "pid"
+	DependencyVolumesFrom        DependencyKind = "volumes_from"
+	DependencyLinks              DependencyKind = "links"
+	DependencyAdditionalContexts DependencyKind = "build.additional_contexts"
+	// DependencyModels is inferred by this library from services with a model provider running a model used
+	// by the service. Unlike other kinds, this dependency isn't defined by the compose specification
+	DependencyModels DependencyKind = "models"
+)
+
+// ModelProviderType is the provider type of services running a model
+const ModelProviderType = "model"
+
+// implicitDependencies returns the services this service depends on as implied by its own attributes other
+// than depends_on, with the kinds of dependency
+func (s ServiceConfig) implicitDependencies() map[string][]DependencyKind {
+	dependencies := map[string][]DependencyKind{}
+	add := func(service string, kind DependencyKind) {
+		if service == "" || slices.Contains(dependencies[service], kind) {
+			return
+		}
+		dependencies[service] = append(dependencies[service], kind)
+	}
+	for _, ref := range []struct {
+		value string
+		kind  DependencyKind
+	}{
+		{value: s.NetworkMode, kind: DependencyNetworkMode},
+		{value: s.Ipc, kind: DependencyIpc},
+		{value: s.Pid, kind: DependencyPid},
+	} {
+		if service, ok := strings.CutPrefix(ref.value, ServicePrefix); ok {
+			add(service, ref.kind)
+		}
+	}
+	for _, v := range s.VolumesFrom {
+		if strings.HasPrefix(v, ContainerPrefix) {
+			continue
+		}
+		service, _, _ := strings.Cut(v, ":")
+		add(service, DependencyVolumesFrom)
+	}
+	for _, l := range s.Links {
+		service, _, _ := strings.Cut(l, ":")
+		add(service, DependencyLinks)
+	}
+	if s.Build != nil {
+		for _, c := range s.Build.AdditionalContexts {
+			if service, ok := strings.CutPrefix(c, ServicePrefix); ok {
+				add(service, DependencyAdditionalContexts)
+			}
+		}
+	}
+	delete(dependencies, s.Name)
+	return dependencies
+}
+
+// ImplicitDependencies returns the services s depends on as implied by attributes other than depends_on,
+// with the kinds of dependency: network_mode, ipc, pid, volumes_from, links and build additional contexts.
+// Services providing a model used by s are also included, see DependencyModels
+func (p *Project) ImplicitDependencies(s ServiceConfig) map[string][]DependencyKind {
+	dependencies := s.implicitDependencies()
+	for model := range s.Models {
+		for _, name := range p.ModelProviders(model) {
+			if name != s.Name && !slices.Contains(dependencies[name], DependencyModels) {
+				dependencies[name] = append(dependencies[name], DependencyModels)
+			}
+		}
+	}
+	return dependencies
+}
+
+// ModelProviders returns the services with a model provider running the top-level model
+func (p *Project) ModelProviders(model string) []string {
+	m, ok := p.Models[model]
+	if !ok || m.Model == "" {
+		return nil
+	}
+	var providers []string
+	for _, name := range p.ServiceNames() {
+		provider := p.Services[name].Provider
+		if provider != nil && provider.Type == ModelProviderType && slices.Contains(provider.Options["model"], m.Model) {
+			providers = append(providers, name)
+		}
+	}
+	return providers
+}
+
+// Dependencies returns the services s depends on. When implicit is set, dependencies implied by attributes
+// other than depends_on are included, requiring the target service to be started, see ImplicitDependencies
+func (p *Project) Dependencies(s ServiceConfig, implicit bool) map[string]ServiceDependency {
+	if !implicit {
+		return s.dependencies(nil)
+	}
+	return s.dependencies(p.ImplicitDependencies(s))
+}
+
+func (s ServiceConfig) dependencies(implicit map[string][]DependencyKind) map[string]ServiceDependency {
+	dependencies := map[string]ServiceDependency{}
+	for name, d := range s.DependsOn {
+		dependencies[name] = d
+	}
+	for name := range implicit {
+		if _, ok := dependencies[name]; ok {
+			continue
+		}
+		dependencies[name] = ServiceDependency{
+			Condition: ServiceConditionStarted,
+			Required:  true,
+		}
+	}
+	return dependencies
+}
+
+// WithImplicitDependencies configures dependency resolution to include dependencies implied by
+// attributes other than depends_on, see Project.ImplicitDependencies
+func WithImplicitDependencies(options *withServicesOptions) {
+	options.implicitDependencies = true
+}
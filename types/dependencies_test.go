@@ -0,0 +1,107 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package types
+
+import (
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/utils"
+	"gotest.tools/v3/assert"
+)
+
+func TestImplicitDependencies(t *testing.T) {
+	s := ServiceConfig{
+		Name:        "app",
+		NetworkMode: "service:proxy",
+		Ipc:         "service:proxy",
+		Pid:         "host",
+		VolumesFrom: []string{"data:ro", "container:legacy", "app"},
+		Links:       []string{"db:database", "cache"},
+		Build: &BuildConfig{
+			AdditionalContexts: Mapping{
+				"base": "service:base",
+				"src":  "./src",
+			},
+		},
+	}
+	assert.DeepEqual(t, s.implicitDependencies(), map[string][]DependencyKind{
+		"proxy": {DependencyNetworkMode, DependencyIpc},
+		"data":  {DependencyVolumesFrom},
+		"db":    {DependencyLinks},
+		"cache": {DependencyLinks},
+		"base":  {DependencyAdditionalContexts},
+	})
+}
+
+func TestProjectImplicitDependencies(t *testing.T) {
+	p := &Project{
+		Models: Models{
+			"llm":   {Model: "ai/smollm2"},
+			"embed": {Model: "ai/mxbai-embed-large"},
+		},
+		Services: Services{
+			"app": {
+				Name:        "app",
+				NetworkMode: "service:proxy",
+				Models:      map[string]*ServiceModelConfig{"llm": {}, "embed": {}},
+			},
+			"proxy": {Name: "proxy"},
+			"runner": {Name: "runner", Provider: &ServiceProviderConfig{
+				Type:    ModelProviderType,
+				Options: MultiOptions{"model": {"ai/smollm2"}},
+			}},
+			"other": {Name: "other", Provider: &ServiceProviderConfig{
+				Type:    "custom",
+				Options: MultiOptions{"model": {"ai/mxbai-embed-large"}},
+			}},
+		},
+	}
+	assert.DeepEqual(t, p.ModelProviders("llm"), []string{"runner"})
+	assert.Equal(t, len(p.ModelProviders("embed")), 0)
+	assert.DeepEqual(t, p.ImplicitDependencies(p.Services["app"]), map[string][]DependencyKind{
+		"proxy":  {DependencyNetworkMode},
+		"runner": {DependencyModels},
+	})
+
+	assert.DeepEqual(t, utils.MapKeys(p.Dependencies(p.Services["app"], true)), []string{"proxy", "runner"})
+	assert.Equal(t, len(p.Dependencies(p.Services["app"], false)), 0)
+
+	selected, err := p.WithSelectedServices([]string{"app"}, WithImplicitDependencies)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, selected.ServiceNames(), []string{"app", "proxy", "runner"})
+}
+
+func TestWithSelectedServicesImplicitDependencies(t *testing.T) {
+	p := &Project{
+		Services: Services{
+			"app":   {Name: "app", NetworkMode: "service:proxy"},
+			"proxy": {Name: "proxy"},
+			"db":    {Name: "db"},
+		},
+	}
+	selected, err := p.WithSelectedServices([]string{"app"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, selected.ServiceNames(), []string{"app"})
+
+	selected, err = p.WithSelectedServices([]string{"app"}, WithImplicitDependencies)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, selected.ServiceNames(), []string{"app", "proxy"})
+
+	selected, err = p.WithSelectedServices([]string{"proxy"}, IncludeDependents, WithImplicitDependencies)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, selected.ServiceNames(), []string{"app", "proxy"})
+}
@@ -783,6 +783,24 @@ func deriveDeepCopyService(dst, src *ServiceConfig) {
 		}
 		deriveDeepCopy_26(dst.PreStop, src.PreStop)
 	}
+	if src.SensitiveEnvironment == nil {
+		dst.SensitiveEnvironment = nil
+	} else {
+		if dst.SensitiveEnvironment != nil {
+			if len(src.SensitiveEnvironment) > len(dst.SensitiveEnvironment) {
+				if cap(dst.SensitiveEnvironment) >= len(src.SensitiveEnvironment) {
+					dst.SensitiveEnvironment = (dst.SensitiveEnvironment)[:len(src.SensitiveEnvironment)]
+				} else {
+					dst.SensitiveEnvironment = make([]string, len(src.SensitiveEnvironment))
+				}
+			} else if len(src.SensitiveEnvironment) < len(dst.SensitiveEnvironment) {
+				dst.SensitiveEnvironment = (dst.SensitiveEnvironment)[:len(src.SensitiveEnvironment)]
+			}
+		} else {
+			dst.SensitiveEnvironment = make([]string, len(src.SensitiveEnvironment))
+		}
+		copy(dst.SensitiveEnvironment, src.SensitiveEnvironment)
+	}
 	if src.Extensions != nil {
 		dst.Extensions = make(map[string]any, len(src.Extensions))
 		src.Extensions.DeepCopy(dst.Extensions)
@@ -0,0 +1,264 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package types
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"net/netip"
+
+	"github.com/compose-spec/compose-go/v2/errdefs"
+	"github.com/compose-spec/compose-go/v2/utils"
+	"github.com/sirupsen/logrus"
+)
+
+// addressPool allocates addresses from a subnet, skipping reserved addresses
+type addressPool struct {
+	subnet   netip.Prefix
+	ipRange  netip.Prefix
+	reserved map[netip.Addr]bool
+}
+
+// allocate returns an available address derived from a hash of key, so that it doesn't depend on other
+// allocations. On collision with a reserved address, the next addresses are probed. Addresses in ip_range
+// are only allocated when no other address is available
+func (a *addressPool) allocate(key string) (netip.Addr, bool) {
+	hostBits := a.subnet.Addr().BitLen() - a.subnet.Bits()
+	mask := uint64(math.MaxUint64)
+	if hostBits < 64 {
+		mask = 1<<hostBits - 1
+	}
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	start := h.Sum64() & mask
+	if addr, ok := a.probe(start, mask, true); ok {
+		return addr, true
+	}
+	return a.probe(start, mask, false)
+}
+
+// probe looks for an available address from offset start. As ip_range is skipped at once, the number
+// of probes is bounded by the number of reserved addresses, even in large subnets
+func (a *addressPool) probe(start, mask uint64, skipRange bool) (netip.Addr, bool) {
+	probes := uint64(len(a.reserved)) + 2
+	if mask < probes {
+		probes = mask + 1
+	}
+	offset := start
+	for range probes {
+		addr := addOffset(a.subnet.Addr(), offset)
+		if skipRange && a.ipRange.IsValid() && a.ipRange.Contains(addr) {
+			// ip_range is left for the engine to allocate dynamic addresses
+			end, ok := a.offset(lastAddr(a.ipRange))
+			if !ok {
+				// ip_range extends beyond the addresses a key can be mapped to
+				end = mask
+			}
+			offset = (end + 1) & mask
+			continue
+		}
+		if !a.reserved[addr] {
+			a.reserved[addr] = true
+			return addr, true
+		}
+		offset = (offset + 1) & mask
+	}
+	return netip.Addr{}, false
+}
+
+// offset returns the offset of addr from the subnet address, if it fits in 64 bits
+func (a *addressPool) offset(addr netip.Addr) (uint64, bool) {
+	base := a.subnet.Addr()
+	if base.Is4() {
+		b, o := base.As4(), addr.As4()
+		return uint64(binary.BigEndian.Uint32(o[:]) - binary.BigEndian.Uint32(b[:])), true
+	}
+	b, o := base.As16(), addr.As16()
+	if !bytes.Equal(b[:8], o[:8]) {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(o[8:]) - binary.BigEndian.Uint64(b[8:]), true
+}
+
+// addOffset returns the address at offset from addr
+func addOffset(addr netip.Addr, offset uint64) netip.Addr {
+	if addr.Is4() {
+		b := addr.As4()
+		v := binary.BigEndian.Uint32(b[:]) + uint32(offset)
+		binary.BigEndian.PutUint32(b[:], v)
+		return netip.AddrFrom4(b)
+	}
+	b := addr.As16()
+	low := binary.BigEndian.Uint64(b[8:])
+	sum := low + offset
+	if sum < low {
+		binary.BigEndian.PutUint64(b[:8], binary.BigEndian.Uint64(b[:8])+1)
+	}
+	binary.BigEndian.PutUint64(b[8:], sum)
+	return netip.AddrFrom16(b)
+}
+
+// lastAddr returns the last address of a prefix, i.e. with all host bits set
+func lastAddr(p netip.Prefix) netip.Addr {
+	b := p.Masked().Addr().AsSlice()
+	for i := p.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 0x80 >> (i % 8)
+	}
+	addr, _ := netip.AddrFromSlice(b)
+	return addr
+}
+
+// WithStaticAddresses returns a new Project with ipv4_address and ipv6_address set for services attached
+// to networks declaring IPAM subnets. Each address is derived from a hash of the network and service names,
+// so adding, removing or renaming a service doesn't change addresses allocated to other services. On collision,
+// the next available address is used, skipping network and broadcast addresses, gateways (first address when
+// not set), aux_addresses and addresses already assigned to services, services being allocated in name order.
+// Addresses in ip_range, reserved for dynamic allocation, are only used once the rest of the subnet is exhausted.
+// Services with more than one replica can't have a static address and are left unchanged, with a warning.
+func (p *Project) WithStaticAddresses() (*Project, error) {
+	n := p.deepCopy()
+	for _, network := range n.NetworkNames() {
+		config := n.Networks[network]
+		if config.External {
+			continue
+		}
+		var v4, v6 []*addressPool
+		for _, ipam := range config.Ipam.Config {
+			if ipam == nil || ipam.Subnet == "" {
+				continue
+			}
+			pool, err := newAddressPool(ipam)
+			if err != nil {
+				return nil, fmt.Errorf("networks.%s: %w", network, err)
+			}
+			if pool.subnet.Addr().Is4() {
+				v4 = append(v4, pool)
+			} else {
+				v6 = append(v6, pool)
+			}
+		}
+		if len(v4) == 0 && len(v6) == 0 {
+			continue
+		}
+
+		var attached []string
+		for _, name := range n.ServiceNames() {
+			s := n.Services[name]
+			if _, ok := s.Networks[network]; !ok {
+				continue
+			}
+			attached = append(attached, name)
+			if c := s.Networks[network]; c != nil {
+				reserve(v4, c.Ipv4Address)
+				reserve(v6, c.Ipv6Address)
+			}
+		}
+
+		for _, name := range attached {
+			s := n.Services[name]
+			if scale := s.GetScale(); scale > 1 {
+				logrus.Warnf("services.%s.networks.%s: no static address allocated to service with %d replicas", name, network, scale)
+				continue
+			}
+			c := s.Networks[network]
+			if c == nil {
+				c = &ServiceNetworkConfig{}
+				s.Networks[network] = c
+			}
+			var err error
+			if c.Ipv4Address == "" && len(v4) > 0 {
+				if c.Ipv4Address, err = allocate(v4, network, name); err != nil {
+					return nil, err
+				}
+			}
+			if c.Ipv6Address == "" && len(v6) > 0 {
+				if c.Ipv6Address, err = allocate(v6, network, name); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return n, nil
+}
+
+func newAddressPool(ipam *IPAMPool) (*addressPool, error) {
+	subnet, err := netip.ParsePrefix(ipam.Subnet)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subnet %q: %w", ipam.Subnet, errdefs.ErrInvalid)
+	}
+	subnet = subnet.Masked()
+	pool := &addressPool{
+		subnet:   subnet,
+		reserved: map[netip.Addr]bool{},
+	}
+	// network address
+	pool.reserved[subnet.Addr()] = true
+	if subnet.Addr().Is4() {
+		// broadcast address
+		pool.reserved[lastAddr(subnet)] = true
+	}
+	if ipam.Gateway != "" {
+		gateway, err := netip.ParseAddr(ipam.Gateway)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gateway %q: %w", ipam.Gateway, errdefs.ErrInvalid)
+		}
+		pool.reserved[gateway] = true
+	} else {
+		// engine assigns the first address as gateway
+		pool.reserved[subnet.Addr().Next()] = true
+	}
+	if ipam.IPRange != "" {
+		ipRange, err := netip.ParsePrefix(ipam.IPRange)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ip_range %q: %w", ipam.IPRange, errdefs.ErrInvalid)
+		}
+		pool.ipRange = ipRange.Masked()
+	}
+	for _, host := range utils.MapKeys(ipam.AuxiliaryAddresses) {
+		addr, err := netip.ParseAddr(ipam.AuxiliaryAddresses[host])
+		if err != nil {
+			return nil, fmt.Errorf("invalid aux_addresses %s %q: %w", host, ipam.AuxiliaryAddresses[host], errdefs.ErrInvalid)
+		}
+		pool.reserved[addr] = true
+	}
+	return pool, nil
+}
+
+// reserve marks an address statically assigned to a service as unavailable
+func reserve(pools []*addressPool, address string) {
+	addr, err := netip.ParseAddr(address)
+	if err != nil {
+		return
+	}
+	for _, pool := range pools {
+		if pool.subnet.Contains(addr) {
+			pool.reserved[addr] = true
+		}
+	}
+}
+
+func allocate(pools []*addressPool, network string, service string) (string, error) {
+	for _, pool := range pools {
+		if addr, ok := pool.allocate(network + "/" + service); ok {
+			return addr.String(), nil
+		}
+	}
+	return "", fmt.Errorf("services.%s.networks.%s: no address available in network subnets: %w", service, network, errdefs.ErrInvalid)
+}
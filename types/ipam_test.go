@@ -0,0 +1,179 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package types
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/errdefs"
+	logrustest "github.com/sirupsen/logrus/hooks/test"
+	"gotest.tools/v3/assert"
+)
+
+func TestWithStaticAddresses(t *testing.T) {
+	scale := 2
+	p := &Project{
+		Networks: Networks{
+			"front": {
+				Ipam: IPAMConfig{Config: []*IPAMPool{
+					{
+						Subnet:             "172.28.0.0/24",
+						Gateway:            "172.28.0.254",
+						IPRange:            "172.28.0.128/25",
+						AuxiliaryAddresses: Mapping{"host1": "172.28.0.2"},
+					},
+					{Subnet: "2001:db8::/64"},
+				}},
+			},
+			"back": {},
+		},
+		Services: Services{
+			"web":    {Name: "web", Networks: map[string]*ServiceNetworkConfig{"front": nil, "back": nil}},
+			"api":    {Name: "api", Networks: map[string]*ServiceNetworkConfig{"front": {Aliases: []string{"backend"}}}},
+			"db":     {Name: "db", Networks: map[string]*ServiceNetworkConfig{"front": {Ipv4Address: "172.28.0.3"}}},
+			"worker": {Name: "worker", Scale: &scale, Networks: map[string]*ServiceNetworkConfig{"front": nil}},
+		},
+	}
+	hook := logrustest.NewGlobal()
+	n, err := p.WithStaticAddresses()
+	assert.NilError(t, err)
+	assert.Equal(t, hook.LastEntry().Message, "services.worker.networks.front: no static address allocated to service with 2 replicas")
+
+	assert.DeepEqual(t, n.Services["api"].Networks["front"], &ServiceNetworkConfig{
+		Aliases:     []string{"backend"},
+		Ipv4Address: "172.28.0.1",
+		Ipv6Address: "2001:db8::88f8:c4e9:ff8f:9cb7",
+	})
+	assert.DeepEqual(t, n.Services["db"].Networks["front"], &ServiceNetworkConfig{
+		Ipv4Address: "172.28.0.3",
+		Ipv6Address: "2001:db8::98a8:dc7f:1108:3cd3",
+	})
+	assert.DeepEqual(t, n.Services["web"].Networks, map[string]*ServiceNetworkConfig{
+		"front": {Ipv4Address: "172.28.0.33", Ipv6Address: "2001:db8::ed3a:5e9:a764:c521"},
+		"back":  nil,
+	})
+	assert.Check(t, n.Services["worker"].Networks["front"] == nil)
+
+	// original project is left unchanged
+	assert.Check(t, p.Services["web"].Networks["front"] == nil)
+	assert.Equal(t, p.Services["api"].Networks["front"].Ipv4Address, "")
+
+	// allocation is stable
+	again, err := p.WithStaticAddresses()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, again, n)
+
+	// adding a service doesn't change addresses allocated to others
+	p.Services["aaa"] = ServiceConfig{Name: "aaa", Networks: map[string]*ServiceNetworkConfig{"front": nil}}
+	again, err = p.WithStaticAddresses()
+	assert.NilError(t, err)
+	for _, name := range []string{"api", "db", "web"} {
+		assert.DeepEqual(t, again.Services[name].Networks["front"], n.Services[name].Networks["front"])
+	}
+	assert.Equal(t, again.Services["aaa"].Networks["front"].Ipv4Address, "172.28.0.50")
+}
+
+func TestWithStaticAddressesExhausted(t *testing.T) {
+	p := &Project{
+		Networks: Networks{
+			"front": {
+				Ipam: IPAMConfig{Config: []*IPAMPool{{Subnet: "172.28.0.0/30"}}},
+			},
+		},
+		Services: Services{
+			"api": {Name: "api", Networks: map[string]*ServiceNetworkConfig{"front": nil}},
+			"web": {Name: "web", Networks: map[string]*ServiceNetworkConfig{"front": nil}},
+		},
+	}
+	_, err := p.WithStaticAddresses()
+	assert.Error(t, err, "services.web.networks.front: no address available in network subnets: invalid compose project")
+	assert.Assert(t, errdefs.IsInvalidError(err))
+}
+
+func TestWithStaticAddressesDynamicRange(t *testing.T) {
+	p := &Project{
+		Networks: Networks{
+			"front": {
+				Ipam: IPAMConfig{Config: []*IPAMPool{
+					{Subnet: "172.28.0.0/24", IPRange: "172.28.0.0/24"},
+					{Subnet: "2001:db8::/64", IPRange: "2001:db8::/64"},
+				}},
+			},
+		},
+		Services: Services{
+			"api": {Name: "api", Networks: map[string]*ServiceNetworkConfig{"front": nil}},
+		},
+	}
+	n, err := p.WithStaticAddresses()
+	assert.NilError(t, err)
+	c := n.Services["api"].Networks["front"]
+	assert.Check(t, netipPrefix(t, "172.28.0.0/24").Contains(netip.MustParseAddr(c.Ipv4Address)))
+	assert.Check(t, netipPrefix(t, "2001:db8::/64").Contains(netip.MustParseAddr(c.Ipv6Address)))
+}
+
+func TestWithStaticAddressesNearlyFull(t *testing.T) {
+	// a single address is left outside ip_range, then addresses are allocated in ip_range
+	p := &Project{
+		Networks: Networks{
+			"front": {
+				Ipam: IPAMConfig{Config: []*IPAMPool{{Subnet: "172.28.0.0/29", IPRange: "172.28.0.4/30"}}},
+			},
+		},
+		Services: Services{
+			"api": {Name: "api", Networks: map[string]*ServiceNetworkConfig{"front": nil}},
+			"db":  {Name: "db", Networks: map[string]*ServiceNetworkConfig{"front": nil}},
+			"web": {Name: "web", Networks: map[string]*ServiceNetworkConfig{"front": nil}},
+		},
+	}
+	n, err := p.WithStaticAddresses()
+	assert.NilError(t, err)
+	assert.Equal(t, n.Services["api"].Networks["front"].Ipv4Address, "172.28.0.2")
+	assert.Equal(t, n.Services["db"].Networks["front"].Ipv4Address, "172.28.0.3")
+	assert.Check(t, netipPrefix(t, "172.28.0.4/30").Contains(netip.MustParseAddr(n.Services["web"].Networks["front"].Ipv4Address)))
+}
+
+func TestWithStaticAddressesLargeDynamicRange(t *testing.T) {
+	// ip_range covers most of the subnet, the few addresses left must still be found
+	p := &Project{
+		Networks: Networks{
+			"front": {
+				Ipam: IPAMConfig{Config: []*IPAMPool{{Subnet: "2001:db8::/64", IPRange: "2001:db8::8000:0:0:0/65"}}},
+			},
+		},
+		Services: Services{
+			"api": {Name: "api", Networks: map[string]*ServiceNetworkConfig{"front": nil}},
+		},
+	}
+	n, err := p.WithStaticAddresses()
+	assert.NilError(t, err)
+	addr := netip.MustParseAddr(n.Services["api"].Networks["front"].Ipv6Address)
+	assert.Check(t, !netipPrefix(t, "2001:db8::8000:0:0:0/65").Contains(addr), addr)
+}
+
+func TestLastAddr(t *testing.T) {
+	assert.Equal(t, lastAddr(netipPrefix(t, "172.28.5.0/24")).String(), "172.28.5.255")
+	assert.Equal(t, lastAddr(netipPrefix(t, "10.0.0.0/14")).String(), "10.3.255.255")
+	assert.Equal(t, lastAddr(netipPrefix(t, "2001:db8::/112")).String(), "2001:db8::ffff")
+}
+
+func netipPrefix(t *testing.T, s string) netip.Prefix {
+	t.Helper()
+	p, err := netip.ParsePrefix(s)
+	assert.NilError(t, err)
+	return p
+}
@@ -0,0 +1,151 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package types
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/errdefs"
+)
+
+// hostPort is a host port range published by a service
+type hostPort struct {
+	service  string
+	start    int
+	end      int
+	protocol string
+	// ip is nil for a wildcard address, ipv4 and ipv6 tell the address families bound
+	ip   net.IP
+	ipv4 bool
+	ipv6 bool
+}
+
+func (p hostPort) String() string {
+	s := strconv.Itoa(p.start)
+	if p.end != p.start {
+		s = fmt.Sprintf("%d-%d", p.start, p.end)
+	}
+	if p.ip != nil {
+		s = net.JoinHostPort(p.ip.String(), s)
+	}
+	return s + "/" + p.protocol
+}
+
+// CheckPortConflicts validate host ports published by services don't collide, considering
+// service replicas, port ranges, host_ip wildcards and address families
+func (p *Project) CheckPortConflicts() error {
+	var ports []hostPort
+	for _, name := range p.ServiceNames() {
+		s := p.Services[name]
+		scale := s.GetScale()
+		if scale == 0 {
+			continue
+		}
+		for _, port := range s.Ports {
+			hp, ok := parseHostPort(name, port)
+			if !ok {
+				continue
+			}
+			if size := hp.end - hp.start + 1; size < scale {
+				if size == 1 {
+					return fmt.Errorf("service %q publishes host port %s but has %d replicas: %w", name, hp, scale, errdefs.ErrInvalid)
+				}
+				return fmt.Errorf("service %q publishes host port range %s which is too small for %d replicas: %w", name, hp, scale, errdefs.ErrInvalid)
+			}
+			for _, other := range ports {
+				if overlap, ok := hp.overlaps(other); ok {
+					if other.service == name {
+						return fmt.Errorf("service %q publishes host port %s more than once: %w", name, overlap, errdefs.ErrInvalid)
+					}
+					return fmt.Errorf("services %q and %q both publish host port %s: %w", other.service, name, overlap, errdefs.ErrInvalid)
+				}
+			}
+			ports = append(ports, hp)
+		}
+	}
+	return nil
+}
+
+func parseHostPort(service string, port ServicePortConfig) (hostPort, bool) {
+	if port.Published == "" {
+		return hostPort{}, false
+	}
+	p := hostPort{
+		service:  service,
+		protocol: port.Protocol,
+	}
+	if p.protocol == "" {
+		p.protocol = "tcp"
+	}
+	start, end, isRange := strings.Cut(port.Published, "-")
+	var err error
+	if p.start, err = strconv.Atoi(start); err != nil || p.start == 0 {
+		return hostPort{}, false
+	}
+	p.end = p.start
+	if isRange {
+		if p.end, err = strconv.Atoi(end); err != nil || p.end < p.start {
+			return hostPort{}, false
+		}
+	}
+
+	switch hostIP := strings.Trim(port.HostIP, "[]"); hostIP {
+	case "":
+		p.ipv4, p.ipv6 = true, true
+	case "0.0.0.0":
+		p.ipv4 = true
+	case "::":
+		p.ipv6 = true
+	default:
+		p.ip = net.ParseIP(hostIP)
+		if p.ip == nil {
+			return hostPort{}, false
+		}
+		if p.ip.To4() != nil {
+			p.ipv4 = true
+		} else {
+			p.ipv6 = true
+		}
+	}
+	return p, true
+}
+
+// overlaps returns the host ports bound by both p and other
+func (p hostPort) overlaps(other hostPort) (hostPort, bool) {
+	if p.protocol != other.protocol {
+		return hostPort{}, false
+	}
+	if !(p.ipv4 && other.ipv4) && !(p.ipv6 && other.ipv6) {
+		return hostPort{}, false
+	}
+	if p.ip != nil && other.ip != nil && !p.ip.Equal(other.ip) {
+		return hostPort{}, false
+	}
+	overlap := hostPort{
+		start:    max(p.start, other.start),
+		end:      min(p.end, other.end),
+		protocol: p.protocol,
+		ip:       p.ip,
+	}
+	if overlap.ip == nil {
+		overlap.ip = other.ip
+	}
+	return overlap, overlap.start <= overlap.end
+}
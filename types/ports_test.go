@@ -0,0 +1,134 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package types
+
+import (
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/errdefs"
+	"gotest.tools/v3/assert"
+)
+
+func TestCheckPortConflicts(t *testing.T) {
+	scale := func(i int) *int { return &i }
+	tests := []struct {
+		name     string
+		services Services
+		expected string
+	}{
+		{
+			name: "distinct ports",
+			services: Services{
+				"web": {Name: "web", Ports: []ServicePortConfig{{Target: 80, Published: "8080"}}},
+				"api": {Name: "api", Ports: []ServicePortConfig{{Target: 80, Published: "8081"}, {Target: 80}}},
+			},
+		},
+		{
+			name: "same port",
+			services: Services{
+				"web": {Name: "web", Ports: []ServicePortConfig{{Target: 80, Published: "8080"}}},
+				"api": {Name: "api", Ports: []ServicePortConfig{{Target: 8000, Published: "8080", Protocol: "tcp"}}},
+			},
+			expected: `services "api" and "web" both publish host port 8080/tcp: invalid compose project`,
+		},
+		{
+			name: "distinct protocols",
+			services: Services{
+				"web": {Name: "web", Ports: []ServicePortConfig{{Target: 53, Published: "53", Protocol: "tcp"}}},
+				"dns": {Name: "dns", Ports: []ServicePortConfig{{Target: 53, Published: "53", Protocol: "udp"}}},
+			},
+		},
+		{
+			name: "overlapping ranges",
+			services: Services{
+				"web": {Name: "web", Ports: []ServicePortConfig{{Target: 80, Published: "8000-8010"}}},
+				"api": {Name: "api", Ports: []ServicePortConfig{{Target: 80, Published: "8005-8020"}}},
+			},
+			expected: `services "api" and "web" both publish host port 8005-8010/tcp: invalid compose project`,
+		},
+		{
+			name: "distinct host addresses",
+			services: Services{
+				"web": {Name: "web", Ports: []ServicePortConfig{{Target: 80, Published: "8080", HostIP: "127.0.0.1"}}},
+				"api": {Name: "api", Ports: []ServicePortConfig{{Target: 80, Published: "8080", HostIP: "127.0.0.2"}}},
+			},
+		},
+		{
+			name: "wildcard and specific address",
+			services: Services{
+				"web": {Name: "web", Ports: []ServicePortConfig{{Target: 80, Published: "8080", HostIP: "127.0.0.1"}}},
+				"api": {Name: "api", Ports: []ServicePortConfig{{Target: 80, Published: "8080"}}},
+			},
+			expected: `services "api" and "web" both publish host port 127.0.0.1:8080/tcp: invalid compose project`,
+		},
+		{
+			name: "distinct address families",
+			services: Services{
+				"web": {Name: "web", Ports: []ServicePortConfig{{Target: 80, Published: "8080", HostIP: "0.0.0.0"}}},
+				"api": {Name: "api", Ports: []ServicePortConfig{{Target: 80, Published: "8080", HostIP: "::"}}},
+			},
+		},
+		{
+			name: "ipv6 wildcard and address",
+			services: Services{
+				"web": {Name: "web", Ports: []ServicePortConfig{{Target: 80, Published: "8080", HostIP: "[::1]"}}},
+				"api": {Name: "api", Ports: []ServicePortConfig{{Target: 80, Published: "8080", HostIP: "::"}}},
+			},
+			expected: `services "api" and "web" both publish host port [::1]:8080/tcp: invalid compose project`,
+		},
+		{
+			name: "same service",
+			services: Services{
+				"web": {Name: "web", Ports: []ServicePortConfig{{Target: 80, Published: "8080"}, {Target: 81, Published: "8080"}}},
+			},
+			expected: `service "web" publishes host port 8080/tcp more than once: invalid compose project`,
+		},
+		{
+			name: "fixed port with replicas",
+			services: Services{
+				"web": {Name: "web", Scale: scale(2), Ports: []ServicePortConfig{{Target: 80, Published: "8080"}}},
+			},
+			expected: `service "web" publishes host port 8080/tcp but has 2 replicas: invalid compose project`,
+		},
+		{
+			name: "range with replicas",
+			services: Services{
+				"web": {Name: "web", Deploy: &DeployConfig{Replicas: scale(3)}, Ports: []ServicePortConfig{{Target: 80, Published: "8080-8081"}}},
+			},
+			expected: `service "web" publishes host port range 8080-8081/tcp which is too small for 3 replicas: invalid compose project`,
+		},
+		{
+			name: "scaled to zero",
+			services: Services{
+				"web": {Name: "web", Scale: scale(0), Ports: []ServicePortConfig{{Target: 80, Published: "8080"}}},
+				"api": {Name: "api", Ports: []ServicePortConfig{{Target: 80, Published: "8080"}}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Project{Services: tt.services}
+			err := p.CheckPortConflicts()
+			if tt.expected == "" {
+				assert.NilError(t, err)
+				return
+			}
+			assert.Error(t, err, tt.expected)
+			assert.Assert(t, errdefs.IsInvalidError(err))
+		})
+	}
+}
@@ -275,7 +275,8 @@ func (p *Project) ForEachService(names []string, fn ServiceFunc, options ...Depe
 }
 
 type withServicesOptions struct {
-	dependencyPolicy int
+	dependencyPolicy     int
+	implicitDependencies bool
 }
 
 const (
@@ -308,9 +309,9 @@ func (p *Project) withServices(names []string, fn ServiceFunc, seen map[string]b
 		var dependencies map[string]ServiceDependency
 		switch opts.dependencyPolicy {
 		case includeDependents:
-			dependencies = utils.MapsAppend(dependencies, p.dependentsForService(service))
+			dependencies = utils.MapsAppend(dependencies, p.dependentsForService(service, opts.implicitDependencies))
 		case includeDependencies:
-			dependencies = utils.MapsAppend(dependencies, service.DependsOn)
+			dependencies = utils.MapsAppend(dependencies, p.Dependencies(service, opts.implicitDependencies))
 		case ignoreDependencies:
 			// Noop
 		}
@@ -328,13 +329,13 @@ func (p *Project) withServices(names []string, fn ServiceFunc, seen map[string]b
 }
 
 func (p *Project) GetDependentsForService(s ServiceConfig, filter ...func(ServiceDependency) bool) []string {
-	return utils.MapKeys(p.dependentsForService(s, filter...))
+	return utils.MapKeys(p.dependentsForService(s, false, filter...))
 }
 
-func (p *Project) dependentsForService(s ServiceConfig, filter ...func(ServiceDependency) bool) map[string]ServiceDependency {
+func (p *Project) dependentsForService(s ServiceConfig, implicit bool, filter ...func(ServiceDependency) bool) map[string]ServiceDependency {
 	dependent := make(map[string]ServiceDependency)
 	for _, service := range p.Services {
-		for name, dependency := range service.DependsOn {
+		for name, dependency := range p.Dependencies(service, implicit) {
 			if name == s.Name {
 				depends := true
 				for _, f := range filter {
@@ -678,13 +679,20 @@ func resolveImageDigest(image string, resolver func(named reference.Named) (godi
 }
 
 type marshallOptions struct {
-	secretsContent bool
+	secretsContent       bool
+	sensitiveEnvironment bool
 }
 
 func WithSecretContent(o *marshallOptions) {
 	o.secretsContent = true
 }
 
+// WithSensitiveEnvironment includes services environment variables read from sensitive env_file formats,
+// which are excluded by default to prevent sensitive data leaks
+func WithSensitiveEnvironment(o *marshallOptions) {
+	o.sensitiveEnvironment = true
+}
+
 func (opt *marshallOptions) apply(p *Project) *Project {
 	if opt.secretsContent {
 		p = p.deepCopy()
@@ -693,9 +701,27 @@ func (opt *marshallOptions) apply(p *Project) *Project {
 			p.Secrets[name] = config
 		}
 	}
+	if !opt.sensitiveEnvironment && p.hasSensitiveEnvironment() {
+		p = p.deepCopy()
+		for name, service := range p.Services {
+			for _, k := range service.SensitiveEnvironment {
+				delete(service.Environment, k)
+			}
+			p.Services[name] = service
+		}
+	}
 	return p
 }
 
+func (p *Project) hasSensitiveEnvironment() bool {
+	for _, service := range p.Services {
+		if len(service.SensitiveEnvironment) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
 func applyMarshallOptions(p *Project, options ...func(*marshallOptions)) *Project {
 	opts := &marshallOptions{}
 	for _, option := range options {
@@ -745,16 +771,51 @@ func (p *Project) MarshalJSON(options ...func(*marshallOptions)) ([]byte, error)
 	return json.MarshalIndent(m, "", "  ")
 }
 
+// EnvFileOption configures parsing of env_file entries resolving services environment
+type EnvFileOption func(*envFileOptions)
+
+type envFileOptions struct {
+	parsers      map[string]dotenv.Parser
+	detectFormat bool
+}
+
+// WithEnvFileParser sets the parser for an env_file format, overriding the one registered by dotenv package
+// for this project only
+func WithEnvFileParser(format string, parser dotenv.Parser) EnvFileOption {
+	return func(o *envFileOptions) {
+		o.parsers[format] = parser
+	}
+}
+
+// WithEnvFileFormatDetection sets the format of env_file entries without an explicit one from the file
+// extension, see dotenv.DetectFormat. Without this option, those are parsed as dotenv files
+func WithEnvFileFormatDetection(o *envFileOptions) {
+	o.detectFormat = true
+}
+
 // WithServicesEnvironmentResolved parses env_files set for services to resolve the actual environment map for services
 // It returns a new Project instance with the changes and keep the original Project unchanged
-func (p Project) WithServicesEnvironmentResolved(discardEnvFiles bool) (*Project, error) {
+func (p Project) WithServicesEnvironmentResolved(discardEnvFiles bool, options ...EnvFileOption) (*Project, error) {
+	opts := envFileOptions{parsers: map[string]dotenv.Parser{}}
+	for _, option := range options {
+		option(&opts)
+	}
 	newProject := p.deepCopy()
 	for i, service := range newProject.Services {
 		service.Environment = service.Environment.Resolve(newProject.Environment.Resolve)
 
 		environment := service.Environment.ToMapping()
-		for _, envFile := range service.EnvFiles {
-			err := loadEnvFile(envFile, environment, func(k string) (string, bool) {
+		sensitive := map[string]bool{}
+		if opts.detectFormat {
+			service.EnvFiles = slices.Clone(service.EnvFiles)
+		}
+		for j, envFile := range service.EnvFiles {
+			if opts.detectFormat && envFile.Format == "" {
+				envFile.Format = dotenv.DetectFormat(envFile.Path)
+				service.EnvFiles[j] = envFile
+			}
+			before := environment.Clone()
+			err := loadEnvFile(envFile, environment, opts.parsers, func(k string) (string, bool) {
 				// project.env has precedence doing interpolation
 				if resolve, ok := p.Environment.Resolve(k); ok {
 					return resolve, true
@@ -768,8 +829,21 @@ func (p Project) WithServicesEnvironmentResolved(discardEnvFiles bool) (*Project
 			if err != nil {
 				return nil, err
 			}
+			for k, v := range environment {
+				if previous, ok := before[k]; !ok || previous != v {
+					sensitive[k] = dotenv.IsSensitiveFormat(envFile.Format)
+				}
+			}
 		}
 
+		service.SensitiveEnvironment = nil
+		for k, s := range sensitive {
+			if _, overridden := service.Environment[k]; s && !overridden {
+				service.SensitiveEnvironment = append(service.SensitiveEnvironment, k)
+			}
+		}
+		slices.Sort(service.SensitiveEnvironment)
+
 		service.Environment = environment.ToMappingWithEquals().OverrideBy(service.Environment)
 
 		if discardEnvFiles {
@@ -818,7 +892,38 @@ func (p Project) WithServicesLabelsResolved(discardLabelFiles bool) (*Project, e
 	return newProject, nil
 }
 
-func loadEnvFile(envFile EnvFile, environment Mapping, resolve dotenv.LookupFn) error {
+// LintEnvFiles checks services env_file entries using the `.env` format for common mistakes.
+// Variables are looked up from project environment, then service environment
+func (p *Project) LintEnvFiles() ([]dotenv.LintFinding, error) {
+	var findings []dotenv.LintFinding
+	for _, name := range p.ServiceNames() {
+		service := p.Services[name]
+		for _, envFile := range service.EnvFiles {
+			if envFile.Format != "" && envFile.Format != dotenv.DotEnv {
+				continue
+			}
+			if _, err := os.Stat(envFile.Path); os.IsNotExist(err) && !bool(envFile.Required) {
+				continue
+			}
+			f, err := dotenv.LintFile(envFile.Path, func(k string) (string, bool) {
+				if resolve, ok := p.Environment.Resolve(k); ok {
+					return resolve, true
+				}
+				if s, ok := service.Environment[k]; ok && s != nil {
+					return *s, true
+				}
+				return "", false
+			})
+			if err != nil {
+				return nil, err
+			}
+			findings = append(findings, f...)
+		}
+	}
+	return findings, nil
+}
+
+func loadEnvFile(envFile EnvFile, environment Mapping, parsers map[string]dotenv.Parser, resolve dotenv.LookupFn) error {
 	if _, err := os.Stat(envFile.Path); os.IsNotExist(err) {
 		if envFile.Required {
 			return fmt.Errorf("env file %s not found: %w", envFile.Path, err)
@@ -826,7 +931,7 @@ func loadEnvFile(envFile EnvFile, environment Mapping, resolve dotenv.LookupFn)
 		return nil
 	}
 
-	err := loadMappingFile(envFile.Path, envFile.Format, environment, resolve)
+	err := loadMappingFile(envFile.Path, envFile.Format, environment, parsers, resolve)
 	return err
 }
 
@@ -836,17 +941,20 @@ func loadLabelFile(labelFile string, resolve dotenv.LookupFn) (Mapping, error) {
 	}
 
 	labels := Mapping{}
-	err := loadMappingFile(labelFile, "", labels, resolve)
+	err := loadMappingFile(labelFile, "", labels, nil, resolve)
 	return labels, err
 }
 
-func loadMappingFile(path string, format string, vars Mapping, resolve dotenv.LookupFn) error {
+func loadMappingFile(path string, format string, vars Mapping, parsers map[string]dotenv.Parser, resolve dotenv.LookupFn) error {
 	file, err := os.Open(path)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
+	if parser, ok := parsers[format]; ok {
+		return parser(file, path, vars, resolve)
+	}
 	return dotenv.ParseWithFormat(file, path, vars, resolve, format)
 }
 
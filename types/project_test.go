@@ -17,9 +17,12 @@
 package types
 
 import (
+	"bytes"
 	_ "crypto/sha256"
 	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"slices"
 	"sort"
 	"strings"
@@ -27,6 +30,8 @@ import (
 	"sync/atomic"
 	"testing"
 
+	"filippo.io/age"
+	"github.com/compose-spec/compose-go/v2/dotenv"
 	"github.com/compose-spec/compose-go/v2/utils"
 	"github.com/distribution/reference"
 	"github.com/opencontainers/go-digest"
@@ -656,6 +661,144 @@ func TestProject_WithServicesEnvironmentResolved(t *testing.T) {
 	})
 }
 
+func TestProject_WithServicesEnvironmentResolvedSensitive(t *testing.T) {
+	identity, err := age.GenerateX25519Identity()
+	assert.NilError(t, err)
+	var encrypted bytes.Buffer
+	err = dotenv.EncryptAge(&encrypted, strings.NewReader("PASSWORD=s3cr3t\nBAR=from_encrypted\nFOO=from_encrypted\n"), identity.Recipient())
+	assert.NilError(t, err)
+	envFile := filepath.Join(t.TempDir(), "secrets.env.age")
+	assert.NilError(t, os.WriteFile(envFile, encrypted.Bytes(), 0o600))
+
+	p := &Project{
+		Services: Services{
+			"base": ServiceConfig{
+				Name: "base",
+				Environment: MappingWithEquals{
+					"FOO": ptr("foo_from_environment"),
+				},
+				EnvFiles: []EnvFile{
+					{Path: envFile, Format: dotenv.Age},
+					{Path: "fixtures/override.env"},
+				},
+			},
+		},
+		Environment: map[string]string{
+			"COMPOSE_AGE_KEY": identity.String(),
+		},
+	}
+	p, err = p.WithServicesEnvironmentResolved(false)
+	assert.NilError(t, err)
+	service := p.Services["base"]
+	assert.Equal(t, *service.Environment["PASSWORD"], "s3cr3t")
+	// FOO is overridden by service.environment, BAR by override.env
+	assert.DeepEqual(t, service.SensitiveEnvironment, []string{"PASSWORD"})
+
+	yaml, err := p.MarshalYAML()
+	assert.NilError(t, err)
+	assert.Check(t, !strings.Contains(string(yaml), "s3cr3t"))
+	assert.Equal(t, *p.Services["base"].Environment["PASSWORD"], "s3cr3t")
+
+	yaml, err = p.MarshalYAML(WithSensitiveEnvironment)
+	assert.NilError(t, err)
+	assert.Check(t, strings.Contains(string(yaml), "s3cr3t"))
+}
+
+func TestProject_WithServicesEnvironmentResolvedFormatDetection(t *testing.T) {
+	yamlFile := filepath.Join(t.TempDir(), "service.yaml")
+	assert.NilError(t, os.WriteFile(yamlFile, []byte("DB:\n  HOST: localhost\n"), 0o600))
+	p := &Project{
+		Services: Services{
+			"base": ServiceConfig{
+				Name:     "base",
+				EnvFiles: []EnvFile{{Path: yamlFile}},
+			},
+		},
+	}
+
+	// without detection, env_file is parsed as dotenv
+	resolved, err := p.WithServicesEnvironmentResolved(false)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, resolved.Services["base"].Environment, MappingWithEquals{
+		"DB":   ptr(""),
+		"HOST": ptr("localhost"),
+	})
+
+	resolved, err = p.WithServicesEnvironmentResolved(false, WithEnvFileFormatDetection)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, resolved.Services["base"].Environment, MappingWithEquals{"DB_HOST": ptr("localhost")})
+	assert.Equal(t, resolved.Services["base"].EnvFiles[0].Format, dotenv.YAML)
+	assert.Equal(t, p.Services["base"].EnvFiles[0].Format, "")
+}
+
+func TestProject_MarshalSecretContentAndSensitiveEnvironment(t *testing.T) {
+	p := &Project{
+		Services: Services{
+			"base": ServiceConfig{
+				Name: "base",
+				Environment: MappingWithEquals{
+					"PASSWORD": ptr("s3cr3t"),
+					"FOO":      ptr("foo"),
+				},
+				SensitiveEnvironment: []string{"PASSWORD"},
+			},
+		},
+		Secrets: Secrets{
+			"token": SecretConfig{Content: "t0k3n"},
+		},
+	}
+
+	for _, marshal := range []func(...func(*marshallOptions)) ([]byte, error){p.MarshalYAML, p.MarshalJSON} {
+		out, err := marshal(WithSecretContent)
+		assert.NilError(t, err)
+		assert.Check(t, strings.Contains(string(out), "t0k3n"))
+		assert.Check(t, !strings.Contains(string(out), "s3cr3t"))
+		assert.Check(t, strings.Contains(string(out), "foo"))
+
+		out, err = marshal(WithSecretContent, WithSensitiveEnvironment)
+		assert.NilError(t, err)
+		assert.Check(t, strings.Contains(string(out), "t0k3n"))
+		assert.Check(t, strings.Contains(string(out), "s3cr3t"))
+	}
+	assert.Equal(t, *p.Services["base"].Environment["PASSWORD"], "s3cr3t")
+}
+
+func TestProject_LintEnvFiles(t *testing.T) {
+	envFile := filepath.Join(t.TempDir(), "service.env")
+	err := os.WriteFile(envFile, []byte("FOO=${FOO}\nBAR=${UNDEFINED}\nFOO=${ZOT}\n"), 0o600)
+	assert.NilError(t, err)
+	jsonFile := filepath.Join(t.TempDir(), "service.json")
+	err = os.WriteFile(jsonFile, []byte(`{"FOO": "bar"}`), 0o600)
+	assert.NilError(t, err)
+	p := &Project{
+		Services: Services{
+			"base": ServiceConfig{
+				Name: "base",
+				Environment: MappingWithEquals{
+					"ZOT": ptr("zot_from_environment"),
+				},
+				EnvFiles: []EnvFile{
+					{Path: "fixtures/base.env"},
+					{Path: envFile},
+					{Path: "fixtures/missing.env", Required: false},
+					{Path: jsonFile, Format: dotenv.JSON},
+					{Path: envFile, Format: dotenv.YAML},
+				},
+			},
+		},
+		Environment: map[string]string{
+			"FOO": "FOO_from_os.env",
+		},
+	}
+	findings, err := p.LintEnvFiles()
+	assert.NilError(t, err)
+	assert.Equal(t, len(findings), 2)
+	assert.Equal(t, findings[0].Line, 2)
+	assert.Equal(t, findings[0].Rule, dotenv.LintRuleUndefinedVariable)
+	assert.Equal(t, findings[1].Line, 3)
+	assert.Equal(t, findings[1].Rule, dotenv.LintRuleDuplicateKey)
+}
+
 func ptr[T any](s T) *T {
 	return &s
 }
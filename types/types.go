@@ -142,6 +142,9 @@ type ServiceConfig struct {
 	PostStart       []ServiceHook                    `yaml:"post_start,omitempty" json:"post_start,omitempty"`
 	PreStop         []ServiceHook                    `yaml:"pre_stop,omitempty" json:"pre_stop,omitempty"`
 
+	// SensitiveEnvironment lists Environment keys which values were read from a sensitive env_file format
+	SensitiveEnvironment []string `yaml:"-" json:"-"`
+
 	Extensions Extensions `yaml:"#extensions,inline,omitempty" json:"-"`
 }
 
@@ -0,0 +1,165 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/errdefs"
+	"github.com/compose-spec/compose-go/v2/tree"
+)
+
+// feature is a compose attribute, optionally restricted to a value, and the docker compose
+// version which introduced support for it
+type feature struct {
+	path    tree.Path
+	value   string
+	version string
+}
+
+// features lists attributes introduced after docker compose v2.0
+var features = []feature{
+	{path: "include", version: "2.20.0"},
+	{path: "models", version: "2.38.0"},
+	{path: "configs.*.content", version: "2.23.1"},
+	{path: "configs.*.environment", version: "2.23.1"},
+	{path: "networks.*.enable_ipv4", version: "2.33.1"},
+	{path: "services.*.build.additional_contexts", version: "2.17.0"},
+	{path: "services.*.build.dockerfile_inline", version: "2.17.0"},
+	{path: "services.*.depends_on.*.restart", version: "2.17.0"},
+	{path: "services.*.depends_on.*.required", version: "2.20.0"},
+	{path: "services.*.develop", version: "2.22.0"},
+	{path: "services.*.develop.watch.*.action", value: "sync+restart", version: "2.23.0"},
+	{path: "services.*.develop.watch.*.action", value: "sync+exec", version: "2.32.0"},
+	{path: "services.*.develop.watch.*.exec", version: "2.32.0"},
+	{path: "services.*.env_file.*.required", version: "2.24.0"},
+	{path: "services.*.env_file.*.format", version: "2.30.0"},
+	{path: "services.*.gpus", version: "2.30.0"},
+	{path: "services.*.post_start", version: "2.30.0"},
+	{path: "services.*.pre_stop", version: "2.30.0"},
+	{path: "services.*.label_file", version: "2.32.0"},
+	{path: "services.*.networks.*.gw_priority", version: "2.33.1"},
+	{path: "services.*.use_api_socket", version: "2.35.0"},
+	{path: "services.*.volumes.*.type", value: "image", version: "2.35.0"},
+	{path: "services.*.provider", version: "2.36.0"},
+	{path: "services.*.networks.*.interface_name", version: "2.36.0"},
+	{path: "services.*.models", version: "2.38.0"},
+	{path: "services.*.pre_start", version: "2.40.0"},
+}
+
+// Requirement is an attribute used by a compose model and the docker compose version it requires
+type Requirement struct {
+	Path    tree.Path
+	Version string
+}
+
+// Requirements lists the attributes used by a compose model which require a recent docker compose
+// version, sorted by path. The model is expected as declared by compose file(s), as canonical
+// transformation and default values introduce attributes the user didn't set
+func Requirements(dict map[string]any) []Requirement {
+	var requirements []Requirement
+	collectRequirements(dict, tree.NewPath(), &requirements)
+	slices.SortFunc(requirements, func(a, b Requirement) int {
+		return strings.Compare(a.Path.String(), b.Path.String())
+	})
+	return requirements
+}
+
+func collectRequirements(value any, p tree.Path, requirements *[]Requirement) {
+	for _, f := range features {
+		if !p.Matches(f.path) {
+			continue
+		}
+		if s, ok := value.(string); f.value != "" && (!ok || s != f.value) {
+			continue
+		}
+		*requirements = append(*requirements, Requirement{Path: p, Version: f.version})
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		for k, e := range v {
+			collectRequirements(e, p.Next(k), requirements)
+		}
+	case []any:
+		for i, e := range v {
+			collectRequirements(e, p.Next(strconv.Itoa(i)), requirements)
+		}
+	}
+}
+
+// MinimumVersion returns the minimum docker compose version required by a compose model, or
+// an empty string if none of the known recent attributes are used
+func MinimumVersion(dict map[string]any) string {
+	minimum := ""
+	for _, r := range Requirements(dict) {
+		if CompareVersions(r.Version, minimum) > 0 {
+			minimum = r.Version
+		}
+	}
+	return minimum
+}
+
+// CheckTargetVersion reports attributes used by a compose model which are not supported by the
+// target docker compose version, as errors wrapping errdefs.ErrUnsupported
+func CheckTargetVersion(dict map[string]any, target string) error {
+	if _, err := parseVersion(target); err != nil {
+		return err
+	}
+	var errs []error
+	for _, r := range Requirements(dict) {
+		if CompareVersions(r.Version, target) > 0 {
+			errs = append(errs, fmt.Errorf("%s requires docker compose %s, target is %s: %w", r.Path, r.Version, target, errdefs.ErrUnsupported))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// CompareVersions compares two `major.minor.patch` versions, an optional `v` prefix is ignored.
+// Empty or invalid version is considered lower than any valid one
+func CompareVersions(a, b string) int {
+	va, errA := parseVersion(a)
+	vb, errB := parseVersion(b)
+	switch {
+	case errA != nil && errB != nil:
+		return 0
+	case errA != nil:
+		return -1
+	case errB != nil:
+		return 1
+	}
+	return slices.Compare(va, vb)
+}
+
+func parseVersion(v string) ([]int, error) {
+	parts := strings.Split(strings.TrimPrefix(v, "v"), ".")
+	if len(parts) > 3 {
+		return nil, fmt.Errorf("invalid version %q", v)
+	}
+	version := make([]int, 3)
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid version %q", v)
+		}
+		version[i] = n
+	}
+	return version, nil
+}
@@ -0,0 +1,82 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package validation
+
+import (
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/errdefs"
+	"github.com/compose-spec/compose-go/v2/tree"
+	"go.yaml.in/yaml/v4"
+	"gotest.tools/v3/assert"
+)
+
+const versionedModel = `
+name: test
+services:
+  web:
+    image: nginx
+    use_api_socket: true
+    develop:
+      watch:
+        - path: ./src
+          action: sync
+          target: /src
+        - path: ./conf
+          action: sync+exec
+          target: /etc/nginx
+          exec:
+            command: nginx -s reload
+models:
+  llm:
+    model: ai/smollm2
+`
+
+func TestRequirements(t *testing.T) {
+	var dict map[string]any
+	assert.NilError(t, yaml.Unmarshal([]byte(versionedModel), &dict))
+
+	assert.DeepEqual(t, Requirements(dict), []Requirement{
+		{Path: "models", Version: "2.38.0"},
+		{Path: tree.NewPath("services", "web", "develop"), Version: "2.22.0"},
+		{Path: tree.NewPath("services", "web", "develop", "watch", "1", "action"), Version: "2.32.0"},
+		{Path: tree.NewPath("services", "web", "develop", "watch", "1", "exec"), Version: "2.32.0"},
+		{Path: tree.NewPath("services", "web", "use_api_socket"), Version: "2.35.0"},
+	})
+	assert.Equal(t, MinimumVersion(dict), "2.38.0")
+	assert.Equal(t, MinimumVersion(map[string]any{"services": map[string]any{}}), "")
+}
+
+func TestCheckTargetVersion(t *testing.T) {
+	var dict map[string]any
+	assert.NilError(t, yaml.Unmarshal([]byte(versionedModel), &dict))
+
+	assert.NilError(t, CheckTargetVersion(dict, "v2.38.0"))
+
+	err := CheckTargetVersion(dict, "2.32.1")
+	assert.Assert(t, errdefs.IsUnsupportedError(err))
+	assert.Error(t, err, `models requires docker compose 2.38.0, target is 2.32.1: unsupported attribute
+services.web.use_api_socket requires docker compose 2.35.0, target is 2.32.1: unsupported attribute`)
+
+	assert.Error(t, CheckTargetVersion(dict, "latest"), `invalid version "latest"`)
+}
+
+func TestCompareVersions(t *testing.T) {
+	assert.Equal(t, CompareVersions("2.30.0", "v2.30"), 0)
+	assert.Equal(t, CompareVersions("2.9.0", "2.10.0"), -1)
+	assert.Equal(t, CompareVersions("2.10.0", ""), 1)
+}
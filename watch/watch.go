@@ -0,0 +1,223 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+// Package watch evaluates develop.watch triggers against host file changes
+package watch
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/compose-spec/compose-go/v2/buildcontext"
+	"github.com/compose-spec/compose-go/v2/tree"
+	"github.com/compose-spec/compose-go/v2/types"
+	"github.com/moby/patternmatcher"
+)
+
+// Matcher evaluates the develop.watch triggers of a service
+type Matcher struct {
+	service string
+	// workingDir is the project working directory, relative file paths are resolved from
+	workingDir string
+	triggers   []trigger
+	// context is the local build context, empty when service isn't built from a local context
+	context string
+	// ignore is the build context .dockerignore
+	ignore *patternmatcher.PatternMatcher
+}
+
+type trigger struct {
+	types.Trigger
+	index   int
+	path    string
+	include *patternmatcher.PatternMatcher
+	ignore  *patternmatcher.PatternMatcher
+}
+
+// Match is a trigger fired by a file change
+type Match struct {
+	// Index is the position of the trigger in develop.watch
+	Index  int
+	Action types.WatchAction
+	// Target is the path to sync the file to inside container, for sync actions
+	Target string
+}
+
+// Issue is a misconfiguration of develop.watch triggers
+type Issue struct {
+	Path    tree.Path
+	Message string
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s", i.Path, i.Message)
+}
+
+// New compiles the develop.watch triggers of a service, with the service build context .dockerignore.
+// Relative trigger paths are resolved from project working directory
+func New(project *types.Project, service types.ServiceConfig) (*Matcher, error) {
+	m := &Matcher{
+		service:    service.Name,
+		workingDir: project.WorkingDir,
+	}
+	if service.Build != nil {
+		if context, ok := buildcontext.LocalDir(project.WorkingDir, service.Build.Context); ok {
+			m.context = context
+			ignore, err := buildcontext.IgnoreForDockerfile(context, buildcontext.Dockerfile(context, service.Build))
+			if err != nil {
+				return nil, fmt.Errorf("service %q: %w", service.Name, err)
+			}
+			m.ignore = ignore
+		}
+	}
+	if service.Develop == nil {
+		return m, nil
+	}
+	for i, t := range service.Develop.Watch {
+		compiled := trigger{
+			Trigger: t,
+			index:   i,
+			path:    project.RelativePath(t.Path),
+		}
+		var err error
+		if compiled.ignore, err = patternmatcher.New(t.Ignore); err != nil {
+			return nil, fmt.Errorf("service %q: develop.watch[%d]: %w", service.Name, i, err)
+		}
+		if len(t.Include) > 0 {
+			if compiled.include, err = patternmatcher.New(t.Include); err != nil {
+				return nil, fmt.Errorf("service %q: develop.watch[%d]: %w", service.Name, i, err)
+			}
+		}
+		m.triggers = append(m.triggers, compiled)
+	}
+	return m, nil
+}
+
+// Match returns the triggers fired by a change to file, in declaration order. Relative paths are
+// resolved from project working directory
+func (m *Matcher) Match(file string) []Match {
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(m.workingDir, file)
+	}
+	file = filepath.Clean(file)
+	if m.excluded(file) {
+		return nil
+	}
+	var matches []Match
+	for _, t := range m.triggers {
+		rel, ok := t.match(file)
+		if !ok {
+			continue
+		}
+		match := Match{Index: t.index, Action: t.Action}
+		if isSync(t.Action) {
+			match.Target = t.Target
+			if rel != "." {
+				match.Target = path.Join(t.Target, filepath.ToSlash(rel))
+			}
+		}
+		matches = append(matches, match)
+	}
+	return matches
+}
+
+// excluded checks file is part of the build context but excluded by .dockerignore
+func (m *Matcher) excluded(file string) bool {
+	if m.context == "" {
+		return false
+	}
+	rel, ok := relative(m.context, file)
+	return ok && rel != "." && matches(m.ignore, rel)
+}
+
+// match checks file is watched by trigger and returns the path relative to trigger path
+func (t trigger) match(file string) (string, bool) {
+	rel, ok := relative(t.path, file)
+	if !ok {
+		return "", false
+	}
+	if rel == "." {
+		return rel, true
+	}
+	if t.include != nil && !matches(t.include, rel) {
+		return "", false
+	}
+	return rel, !matches(t.ignore, rel)
+}
+
+// Check reports triggers watching overlapping paths with conflicting actions, and sync triggers watching
+// files outside the build context, which would get out of sync with the image on next rebuild
+func (m *Matcher) Check() []Issue {
+	var issues []Issue
+	for i, t := range m.triggers {
+		for _, other := range m.triggers[i+1:] {
+			if t.Action == other.Action {
+				continue
+			}
+			// a trigger nested in another one conflicts unless the enclosing one ignores it
+			if _, ok := t.match(other.path); ok {
+				issues = append(issues, Issue{
+					Path:    m.path(other.index),
+					Message: fmt.Sprintf("%s action on %s conflicts with %s action on %s", other.Action, other.Path, t.Action, t.Path),
+				})
+			} else if _, ok := other.match(t.path); ok {
+				issues = append(issues, Issue{
+					Path:    m.path(t.index),
+					Message: fmt.Sprintf("%s action on %s conflicts with %s action on %s", t.Action, t.Path, other.Action, other.Path),
+				})
+			}
+		}
+		if isSync(t.Action) && m.context != "" {
+			if _, ok := relative(m.context, t.path); !ok {
+				issues = append(issues, Issue{
+					Path:    m.path(t.index),
+					Message: fmt.Sprintf("%s action on %s which is outside build context %s", t.Action, t.Path, m.context),
+				})
+			}
+		}
+	}
+	return issues
+}
+
+func (m *Matcher) path(index int) tree.Path {
+	return tree.NewPath("services").Next(m.service).Next("develop").Next("watch").Next(strconv.Itoa(index))
+}
+
+func isSync(action types.WatchAction) bool {
+	switch action {
+	case types.WatchActionSync, types.WatchActionSyncRestart, types.WatchActionSyncExec:
+		return true
+	}
+	return false
+}
+
+// relative returns file path relative to dir, if file is dir itself or inside dir
+func relative(dir, file string) (string, bool) {
+	rel, err := filepath.Rel(dir, file)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
+
+// matches checks path or one of its parent directories matches patterns
+func matches(m *patternmatcher.PatternMatcher, path string) bool {
+	matched, err := m.MatchesOrParentMatches(path)
+	return err == nil && matched
+}
@@ -0,0 +1,101 @@
+/*
+   Copyright 2020 The Compose Specification Authors.
+
+   Licensed under the Apache License, Version 2.0 (the "License");
+   you may not use this file except in compliance with the License.
+   You may obtain a copy of the License at
+
+       http://www.apache.org/licenses/LICENSE-2.0
+
+   Unless required by applicable law or agreed to in writing, software
+   distributed under the License is distributed on an "AS IS" BASIS,
+   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+   See the License for the specific language governing permissions and
+   limitations under the License.
+*/
+
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/compose-spec/compose-go/v2/tree"
+	"github.com/compose-spec/compose-go/v2/types"
+	"gotest.tools/v3/assert"
+)
+
+func watchProject(t *testing.T) *types.Project {
+	dir := t.TempDir()
+	assert.NilError(t, os.MkdirAll(filepath.Join(dir, "app"), 0o755))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "app", ".dockerignore"), []byte("node_modules\n*.log\n"), 0o600))
+	return &types.Project{
+		WorkingDir: dir,
+		Services: types.Services{
+			"web": {
+				Name:  "web",
+				Build: &types.BuildConfig{Context: "app"},
+				Develop: &types.DevelopConfig{
+					Watch: []types.Trigger{
+						{Path: "app/src", Action: types.WatchActionSync, Target: "/app/src", Ignore: []string{"*.test.js"}},
+						{Path: "app/package.json", Action: types.WatchActionRebuild},
+						{Path: "app", Action: types.WatchActionSyncRestart, Target: "/app", Ignore: []string{"src", "package.json"}},
+						{Path: "app/src/config", Action: types.WatchActionRestart},
+						{Path: "shared", Action: types.WatchActionSync, Target: "/shared", Include: []string{"*.json"}},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestMatch(t *testing.T) {
+	project := watchProject(t)
+	m, err := New(project, project.Services["web"])
+	assert.NilError(t, err)
+
+	dir := project.WorkingDir
+	for file, expected := range map[string][]Match{
+		"app/src/main.js":      {{Index: 0, Action: types.WatchActionSync, Target: "/app/src/main.js"}},
+		"app/src/main.test.js": nil,
+		"app/node_modules/x":   nil,
+		"app/debug.log":        nil,
+		"app/package.json":     {{Index: 1, Action: types.WatchActionRebuild}},
+		"app/README.md":        {{Index: 2, Action: types.WatchActionSyncRestart, Target: "/app/README.md"}},
+		"app/src/config/db.yml": {
+			{Index: 0, Action: types.WatchActionSync, Target: "/app/src/config/db.yml"},
+			{Index: 3, Action: types.WatchActionRestart},
+		},
+		"shared/settings.json": {{Index: 4, Action: types.WatchActionSync, Target: "/shared/settings.json"}},
+		"shared/notes.txt":     nil,
+		"other/file":           nil,
+	} {
+		assert.DeepEqual(t, m.Match(filepath.Join(dir, file)), expected)
+		// relative paths are resolved from project working directory, not current directory
+		assert.DeepEqual(t, m.Match(file), expected)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	project := watchProject(t)
+	m, err := New(project, project.Services["web"])
+	assert.NilError(t, err)
+	assert.DeepEqual(t, m.Check(), []Issue{
+		{
+			Path:    tree.NewPath("services", "web", "develop", "watch", "3"),
+			Message: "restart action on app/src/config conflicts with sync action on app/src",
+		},
+		{
+			Path:    tree.NewPath("services", "web", "develop", "watch", "4"),
+			Message: "sync action on shared which is outside build context " + filepath.Join(project.WorkingDir, "app"),
+		},
+	})
+}
+
+func TestNoDevelop(t *testing.T) {
+	m, err := New(&types.Project{}, types.ServiceConfig{Name: "db", Image: "postgres"})
+	assert.NilError(t, err)
+	assert.Equal(t, len(m.Match("/any/file")), 0)
+	assert.Equal(t, len(m.Check()), 0)
+}